@@ -0,0 +1,325 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/CHESSComputing/DataBookkeeping/dbs"
+	"github.com/CHESSComputing/golib/lexicon"
+	validator "github.com/go-playground/validator/v10"
+)
+
+// helper function to set up DBS with new SQLite database, it returns
+// function which restores DBS settings used by other tests
+func initTestDB(t *testing.T, name string) (*sql.DB, func()) {
+	dbsDB, dbsOwner, dbsSQL, staticDir := dbs.DB, dbs.DBOWNER, dbs.DBSQL, dbs.StaticDir
+	if dbs.RecordValidator == nil {
+		dbs.RecordValidator = validator.New()
+	}
+	if len(lexicon.LexiconPatterns) == 0 {
+		patterns, err := lexicon.LoadPatterns("data/dbs_lexicon.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		lexicon.LexiconPatterns = patterns
+	}
+
+	dburi := dbs.DataSourceName("sqlite3", filepath.Join(t.TempDir(), name))
+	tdb, err := sql.Open("sqlite3", dburi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbs.DB = tdb
+	dbs.DBOWNER = "sqlite"
+	dbs.StaticDir = ""
+	dbs.DBSQL = dbs.LoadSQL("sqlite")
+	if _, err := dbs.Bootstrap(); err != nil {
+		tdb.Close()
+		t.Fatal(err)
+	}
+	return tdb, func() {
+		tdb.Close()
+		dbs.DB, dbs.DBOWNER, dbs.DBSQL, dbs.StaticDir = dbsDB, dbsOwner, dbsSQL, staticDir
+	}
+}
+
+// TestConcurrentInserts tests that datasets injected in parallel get unique
+// and dense ids and share records of lookup tables
+func TestConcurrentInserts(t *testing.T) {
+	tdb, restore := initTestDB(t, "concurrency.db")
+	defer restore()
+
+	// all datasets share site, processing, osinfo, environment, script and
+	// input file while every dataset has its own config and output file
+	ndatasets := 200
+	var wg sync.WaitGroup
+	errs := make(chan error, ndatasets)
+	for i := 0; i < ndatasets; i++ {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			rec := map[string]any{
+				"did":          fmt.Sprintf("/beamline=3a/btr=concurrent/cycle=2024-3/sample_name=s%d", idx),
+				"osinfo":       map[string]any{"name": "linux-cc7", "kernel": "1-2-3", "version": "cc7-123"},
+				"environments": []map[string]any{{"name": "conda-concurrent", "version": "1.0", "details": "details"}},
+				"processing":   "concurrent-processing",
+				"site":         "Cornell",
+				"config":       map[string]any{"content": map[string]any{"sample": idx}},
+				"scripts":      []map[string]any{{"name": "concurrent-script", "options": "-m -p"}},
+				"input_files":  []map[string]any{{"name": "/concurrent/input.png"}},
+				"output_files": []map[string]any{{"name": fmt.Sprintf("/concurrent/file%d.png", idx)}},
+			}
+			data, err := json.Marshal(rec)
+			if err != nil {
+				errs <- err
+				return
+			}
+			api := dbs.API{
+				Reader:      bytes.NewReader(data),
+				Writer:      httptest.NewRecorder(),
+				ContentType: "application/json",
+				Params:      make(map[string]any),
+				CreateBy:    "test",
+				Api:         "dataset",
+			}
+			errs <- api.InsertDataset()
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// ids of tables should be unique and dense, i.e. 1..N
+	tables := map[string]struct {
+		id    string
+		count int64
+	}{
+		"datasets":     {"dataset_id", int64(ndatasets)},
+		"files":        {"file_id", int64(ndatasets + 1)},
+		"configs":      {"config_id", int64(ndatasets)},
+		"sites":        {"site_id", 1},
+		"processing":   {"processing_id", 1},
+		"osinfo":       {"os_id", 1},
+		"environments": {"environment_id", 1},
+		"scripts":      {"script_id", 1},
+	}
+	for table, v := range tables {
+		var count, ids, minId, maxId int64
+		stm := fmt.Sprintf("SELECT COUNT(*), COUNT(DISTINCT %s), MIN(%s), MAX(%s) FROM %s", v.id, v.id, v.id, table)
+		if err := tdb.QueryRow(stm).Scan(&count, &ids, &minId, &maxId); err != nil {
+			t.Fatal(err)
+		}
+		if count != v.count || ids != v.count || minId != 1 || maxId != v.count {
+			t.Errorf("table %s has %d records with %d distinct ids in range [%d, %d], expect %d",
+				table, count, ids, minId, maxId, v.count)
+		}
+	}
+}
+
+// siteInserter inserts given site record within transaction
+type siteInserter struct {
+	site  string
+	calls int
+}
+
+// Insert implements dbs.Inserter interface
+func (s *siteInserter) Insert(tx *sql.Tx) (int64, error) {
+	s.calls++
+	rec := dbs.Sites{SITE: s.site}
+	return rec.Insert(tx)
+}
+
+// TestGetRecID tests get-or-insert of lookup table records
+func TestGetRecID(t *testing.T) {
+	tdb, restore := initTestDB(t, "recid.db")
+	defer restore()
+
+	tx, err := tdb.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	// new record is inserted once and its id is returned afterwards
+	rec := &siteInserter{site: "Cornell"}
+	sid, err := dbs.GetRecID(tx, rec, "sites", "site_id", "site", rec.site)
+	if err != nil || sid == 0 {
+		t.Fatalf("unable to insert site, id %d error %v", sid, err)
+	}
+	rid, err := dbs.GetRecID(tx, rec, "sites", "site_id", "site", rec.site)
+	if err != nil || rid != sid || rec.calls != 1 {
+		t.Fatalf("wrong id %d of existing site, expect %d, inserts %d, error %v", rid, sid, rec.calls, err)
+	}
+
+	// insert which violates unique constraint is rolled back and
+	// transaction remains usable
+	dup := &siteInserter{site: "Cornell"}
+	if _, err := dbs.GetRecID(tx, dup, "sites", "site_id", "site", "Ithaca"); err == nil {
+		t.Fatal("look-up of site which was not inserted should fail")
+	}
+	var count int64
+	if err := tx.QueryRow("SELECT COUNT(*) FROM sites").Scan(&count); err != nil || count != 1 {
+		t.Fatalf("wrong number of sites %d, error %v", count, err)
+	}
+}
+
+// TestGetRecIDOracleSavepoint tests that savepoint of inserted record is not
+// released on ORACLE which does not support RELEASE SAVEPOINT statement
+func TestGetRecIDOracleSavepoint(t *testing.T) {
+	tdb, restore := initTestDB(t, "savepoint.db")
+	defer restore()
+	dbType := dbs.DBTYPE
+	defer func() {
+		dbs.DBTYPE = dbType
+	}()
+
+	for _, dbtype := range []string{"sqlite3", "oci8"} {
+		dbs.DBTYPE = dbtype
+		tx, err := tdb.Begin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rec := &siteInserter{site: "Cornell"}
+		if _, err := dbs.GetRecID(tx, rec, "sites", "site_id", "site", rec.site); err != nil {
+			tx.Rollback()
+			t.Fatalf("unable to insert site with %s DB type, error %v", dbtype, err)
+		}
+		// savepoint can be released only if GetRecID did not release it
+		_, err = tx.Exec("RELEASE SAVEPOINT insert_sites")
+		if dbtype == "oci8" && err != nil {
+			t.Errorf("savepoint should be kept with %s DB type, error %v", dbtype, err)
+		} else if dbtype != "oci8" && err == nil {
+			t.Errorf("savepoint should be released with %s DB type", dbtype)
+		}
+		tx.Rollback()
+	}
+}
+
+// traceConnector provides connections of given driver which record all
+// executed statements
+type traceConnector struct {
+	dsn    string
+	driver driver.Driver
+	mu     sync.Mutex
+	stmts  []string
+}
+
+// Connect implements driver.Connector interface
+func (c *traceConnector) Connect(context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &traceConn{Conn: conn, connector: c}, nil
+}
+
+// Driver implements driver.Connector interface
+func (c *traceConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// helper function to count recorded statements with given prefix
+func (c *traceConnector) count(prefix string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var n int
+	for _, stm := range c.stmts {
+		if strings.HasPrefix(strings.TrimSpace(stm), prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+// traceConn records statements prepared by wrapped connection
+type traceConn struct {
+	driver.Conn
+	connector *traceConnector
+}
+
+// Prepare implements driver.Conn interface
+func (c *traceConn) Prepare(query string) (driver.Stmt, error) {
+	c.connector.mu.Lock()
+	c.connector.stmts = append(c.connector.stmts, query)
+	c.connector.mu.Unlock()
+	return c.Conn.Prepare(query)
+}
+
+// TestBulkOracleSavepoint tests that savepoints of bulk records are not
+// released on ORACLE which does not support RELEASE SAVEPOINT statement
+func TestBulkOracleSavepoint(t *testing.T) {
+	tdb, restore := initTestDB(t, "bulk.db")
+	defer restore()
+	dbType := dbs.DBTYPE
+	defer func() {
+		dbs.DBTYPE = dbType
+	}()
+	var fname string
+	if err := tdb.QueryRow("SELECT file FROM pragma_database_list WHERE name = 'main'").Scan(&fname); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dbtype := range []string{"sqlite3", "oci8"} {
+		dbs.DBTYPE = dbtype
+		connector := &traceConnector{dsn: dbs.DataSourceName("sqlite3", fname), driver: tdb.Driver()}
+		dbs.DB = sql.OpenDB(connector)
+
+		var records []map[string]any
+		for _, name := range []string{"ok", "missing-parent"} {
+			rec := map[string]any{
+				"did":        fmt.Sprintf("/beamline=3a/btr=%s/cycle=2024-3/sample_name=%s", dbtype, name),
+				"osinfo":     map[string]any{"name": "linux-cc7", "kernel": "1-2-3", "version": "cc7-123"},
+				"processing": "bulk-processing",
+				"site":       "Cornell",
+			}
+			if name == "missing-parent" {
+				rec["parent_did"] = "/beamline=3a/btr=bulk/cycle=2024-3/sample_name=missing"
+			}
+			records = append(records, rec)
+		}
+		data, err := json.Marshal(records)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w := httptest.NewRecorder()
+		api := dbs.API{
+			Reader:      bytes.NewReader(data),
+			Writer:      w,
+			ContentType: "application/json",
+			Params:      make(map[string]any),
+			CreateBy:    "test",
+			Api:         "dataset_bulk",
+		}
+		if err := api.InsertDatasets(); err != nil {
+			t.Fatal(err)
+		}
+		out := w.Body.String()
+		if !strings.Contains(out, `"idx":0,"did":"/beamline=3a/btr=`+dbtype+`/cycle=2024-3/sample_name=ok","status":"inserted"`) ||
+			!strings.Contains(out, `"idx":1,"did":"/beamline=3a/btr=`+dbtype+`/cycle=2024-3/sample_name=missing-parent","status":"failed","code":110`) {
+			t.Errorf("wrong bulk status with %s DB type: %s", dbtype, out)
+		}
+		nrelease := connector.count("RELEASE SAVEPOINT")
+		if dbtype == "oci8" && nrelease != 0 {
+			t.Errorf("savepoints should not be released with %s DB type", dbtype)
+		} else if dbtype != "oci8" && nrelease == 0 {
+			t.Errorf("savepoints should be released with %s DB type", dbtype)
+		}
+		if connector.count("ROLLBACK TO SAVEPOINT bulk_record") != 1 {
+			t.Errorf("failed bulk record should be rolled back with %s DB type", dbtype)
+		}
+		dbs.DB.Close()
+	}
+}
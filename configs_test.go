@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/CHESSComputing/DataBookkeeping/dbs"
+)
+
+// TestConfigPathCondition tests conditions on values of config documents
+func TestConfigPathCondition(t *testing.T) {
+	dbsOwner := dbs.DBOWNER
+	defer func() {
+		dbs.DBOWNER = dbsOwner
+	}()
+
+	tests := []struct {
+		owner, key, val, cond string
+		args                  []interface{}
+	}{
+		{"sqlite", "config.detector.energy", "12.5", "json_extract(c.content, ?) = ?",
+			[]interface{}{`$."detector"."energy"`, 12.5}},
+		{"sqlite", "config.scans.0.name", "scan*", "json_extract(c.content, ?) LIKE ?",
+			[]interface{}{`$."scans"[0]."name"`, "scan%"}},
+		{"mysql", "config.detector.name", "eiger", "JSON_EXTRACT(c.content, ?) = CAST(? AS JSON)",
+			[]interface{}{`$."detector"."name"`, `"eiger"`}},
+		{"postgres", "config.detector.energy", "12", "c.content #> CAST(? AS TEXT[]) = CAST(? AS JSONB)",
+			[]interface{}{"{detector,energy}", "12"}},
+		{"postgres", "config.calibrated", "true", "c.content #> CAST(? AS TEXT[]) = CAST(? AS JSONB)",
+			[]interface{}{"{calibrated}", "true"}},
+		// NaN, infinity and hexadecimal floats are not JSON numbers
+		{"sqlite", "config.detector.mode", "nan", "json_extract(c.content, ?) = ?",
+			[]interface{}{`$."detector"."mode"`, "nan"}},
+		{"sqlite", "config.detector.mode", "inf", "json_extract(c.content, ?) = ?",
+			[]interface{}{`$."detector"."mode"`, "inf"}},
+		{"mysql", "config.detector.mode", "Infinity", "JSON_EXTRACT(c.content, ?) = CAST(? AS JSON)",
+			[]interface{}{`$."detector"."mode"`, `"Infinity"`}},
+		{"postgres", "config.detector.mode", "0x1p-2", "c.content #> CAST(? AS TEXT[]) = CAST(? AS JSONB)",
+			[]interface{}{"{detector,mode}", `"0x1p-2"`}},
+	}
+	for _, v := range tests {
+		dbs.DBOWNER = v.owner
+		cond, args, err := dbs.ConfigPathCondition(v.key, v.val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.TrimSpace(cond) != v.cond {
+			t.Errorf("wrong %s condition %s, expect %s", v.owner, cond, v.cond)
+		}
+		if fmt.Sprintf("%#v", args) != fmt.Sprintf("%#v", v.args) {
+			t.Errorf("wrong %s args %#v, expect %#v", v.owner, args, v.args)
+		}
+	}
+
+	dbs.DBOWNER = "sqlite"
+	for _, key := range []string{"config.", "config.a..b", "config.a'b", `config.a"b`} {
+		if _, _, err := dbs.ConfigPathCondition(key, "1"); err == nil {
+			t.Errorf("invalid config path %s should fail", key)
+		}
+	}
+}
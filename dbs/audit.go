@@ -0,0 +1,114 @@
+package dbs
+
+// DBS audit log module
+//
+// nolint: gocyclo
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/CHESSComputing/golib/utils"
+)
+
+// results of audited API calls
+const (
+	AuditSuccess = "success"
+	AuditFailure = "failure"
+)
+
+// AuditRecord represents single entry of audit log, the entry is recorded
+// for every POST/PUT/DELETE API call and can't be changed afterwards
+type AuditRecord struct {
+	Api         string `json:"api"`          // DBS API name
+	Method      string `json:"method"`       // HTTP method
+	Uri         string `json:"uri"`          // request URI
+	CreateBy    string `json:"create_by"`    // identity of the caller
+	RemoteAddr  string `json:"remote_addr"`  // remote address of the caller
+	PayloadHash string `json:"payload_hash"` // sha256 hash of request payload
+	Result      string `json:"result"`       // success or failure
+	Code        int    `json:"code"`         // DBS error code of failed call
+	CreateAt    int64  `json:"create_at"`    // time of the call
+}
+
+// InsertAudit inserts audit record into DB, the record is inserted within
+// its own transaction and does not depend on the outcome of audited API
+func InsertAudit(rec AuditRecord) error {
+	if rec.CreateAt == 0 {
+		rec.CreateAt = Date()
+	}
+	if rec.Result == "" {
+		rec.Result = AuditSuccess
+	}
+	tx, err := DB.Begin()
+	if err != nil {
+		return Error(err, TransactionErrorCode, "", "dbs.audit.InsertAudit")
+	}
+	defer tx.Rollback()
+	_, err = tx.Exec(getSQL("insert_audit"),
+		rec.Api, rec.Method, rec.Uri, rec.CreateBy, rec.RemoteAddr,
+		rec.PayloadHash, rec.Result, rec.Code, rec.CreateAt)
+	if err != nil {
+		log.Printf("unable to insert audit record %+v, error %v", rec, err)
+		return Error(err, InsertErrorCode, "", "dbs.audit.InsertAudit")
+	}
+	if err := tx.Commit(); err != nil {
+		return Error(err, CommitErrorCode, "", "dbs.audit.InsertAudit")
+	}
+	return nil
+}
+
+// ErrorCode provides DBS error code of given error
+func ErrorCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var e *DBSError
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return GenericErrorCode
+}
+
+// GetAudit provides entries of audit log
+func (a *API) GetAudit() error {
+	var args []interface{}
+	var conds []string
+	keys := []string{"api", "method", "create_by", "remote_addr", "payload_hash", "result", "code", "since", "until"}
+	for k := range a.Params {
+		if !utils.InList(k, append(keys, PaginationKeys...)) {
+			msg := fmt.Sprintf("invalid parameter %s", k)
+			return Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.audit.GetAudit")
+		}
+	}
+	for _, key := range []string{"api", "method", "create_by", "remote_addr", "payload_hash", "result", "code"} {
+		if _, ok := a.Params[key]; ok {
+			conds, args = AddParam(key, "a."+key, a.Params, conds, args)
+		}
+	}
+	// time range of audit entries
+	for _, key := range []string{"since", "until"} {
+		if _, ok := a.Params[key]; !ok {
+			continue
+		}
+		val, _ := getSingleValue(a.Params, key)
+		tstamp, err := parseTimestamp(val)
+		if err != nil {
+			msg := fmt.Sprintf("invalid %s '%s', should be unix time or RFC3339 date", key, val)
+			return Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.audit.GetAudit")
+		}
+		op := ">="
+		if key == "until" {
+			op = "<="
+		}
+		conds = append(conds, fmt.Sprintf(" a.create_at %s %s", op, placeholder(key)))
+		args = append(args, tstamp)
+	}
+
+	stm := WhereClause(getSQL("select_audit"), conds)
+	if err := a.executePage(stm, args...); err != nil {
+		return Error(err, QueryErrorCode, "", "dbs.audit.GetAudit")
+	}
+	return nil
+}
@@ -44,7 +44,7 @@ func (a *API) GetBucket() error {
 	stm = WhereClause(stm, conds)
 
 	// use generic query API to fetch the results from DB
-	err = executeAll(a.Writer, a.Separator, stm, args...)
+	err = a.executePage(stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "", "dbs.buckets.Buckets")
 	}
@@ -103,7 +103,8 @@ func (r *Buckets) Insert(tx *sql.Tx) (int64, error) {
 	} else if Verbose > 1 {
 		log.Printf("Insert Buckets\n%s\n%+v", stm, r)
 	}
-	_, err = tx.Exec(
+	r.BUCKET_ID, err = execInsert(
+		tx,
 		stm,
 		r.BUCKET_ID,
 		r.BUCKET,
@@ -0,0 +1,284 @@
+package dbs
+
+// DBS bulk insertion module
+//
+// nolint: gocyclo
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/CHESSComputing/golib/utils"
+)
+
+// BulkBatchSize defines default number of dataset records committed
+// within single transaction of bulk insertion
+var BulkBatchSize = 100
+
+// statuses of bulk insertion records
+const (
+	BulkInserted = "inserted"
+	BulkExists   = "exists"
+	BulkFailed   = "failed"
+)
+
+// BulkStatus represents insertion status of single record of bulk request
+type BulkStatus struct {
+	Idx    int    `json:"idx"`             // index of record in input stream
+	Did    string `json:"did"`             // dataset identifier
+	Status string `json:"status"`          // inserted, exists or failed
+	Code   int    `json:"code,omitempty"`  // DBS error code of failed record
+	Error  string `json:"error,omitempty"` // error message of failed record
+}
+
+// InsertDatasets inserts stream of dataset records in batches and writes
+// insertion status of every record back to the client. The input stream
+// can be either NDJSON or JSON list of dataset records.
+func (a *API) InsertDatasets() error {
+	for k := range a.Params {
+		if !utils.InList(k, []string{"batch_size"}) {
+			msg := fmt.Sprintf("invalid parameter %s", k)
+			return Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.bulk.InsertDatasets")
+		}
+	}
+	batchSize := BulkBatchSize
+	if _, ok := a.Params["batch_size"]; ok {
+		val, _ := getSingleValue(a.Params, "batch_size")
+		size, err := strconv.Atoi(val)
+		if err != nil || size < 1 {
+			msg := fmt.Sprintf("invalid batch_size '%s', should be positive integer", val)
+			return Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.bulk.InsertDatasets")
+		}
+		batchSize = size
+	}
+	reader, err := newBulkReader(a.Reader)
+	if err != nil {
+		return Error(err, ReaderErrorCode, "", "dbs.bulk.InsertDatasets")
+	}
+	writer := &bulkWriter{Writer: a.Writer, Separator: a.Separator}
+
+	var tx *sql.Tx
+	var batch []BulkStatus
+	idx := 0
+	for {
+		data, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// malformed input stream, we can't proceed further
+			status := BulkStatus{Idx: idx, Status: BulkFailed}
+			status.Code, status.Error = DecodeErrorCode, bulkMessage(err)
+			batch = append(batch, status)
+			break
+		}
+		if tx == nil {
+			tx, err = DB.Begin()
+			if err != nil {
+				return Error(err, TransactionErrorCode, "", "dbs.bulk.InsertDatasets")
+			}
+		}
+		batch = append(batch, insertBulkRecord(tx, idx, data, a.CreateBy))
+		idx++
+		if len(batch) == batchSize {
+			batch = commitBulk(tx, batch)
+			if err := writer.Write(batch); err != nil {
+				return err
+			}
+			tx, batch = nil, nil
+		}
+	}
+	if tx != nil {
+		batch = commitBulk(tx, batch)
+	}
+	if err := writer.Write(batch); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// helper function to insert single dataset record of bulk request within
+// given transaction, failed record is rolled back via transaction savepoint
+func insertBulkRecord(tx *sql.Tx, idx int, data []byte, createBy string) BulkStatus {
+	status := BulkStatus{Idx: idx, Status: BulkFailed}
+	rec := DatasetRecord{}
+	if err := json.Unmarshal(data, &rec); err != nil {
+		status.Code, status.Error = UnmarshalErrorCode, bulkMessage(err)
+		return status
+	}
+	status.Did = rec.Did
+	if err := rec.Validate(); err != nil {
+		status.Code, status.Error = bulkError(err)
+		return status
+	}
+	record := Datasets{
+		DID:       rec.Did,
+		CREATE_BY: createBy,
+		MODIFY_BY: createBy,
+	}
+	record.SetDefaults()
+	if err := record.Validate(); err != nil {
+		status.Code, status.Error = ValidateErrorCode, bulkMessage(err)
+		return status
+	}
+	if did, err := GetID(tx, "datasets", "dataset_id", "did", rec.Did); err == nil && did > 0 {
+		status.Status = BulkExists
+		return status
+	}
+	err := withSavepoint(tx, "bulk_record", func() error {
+		return insertDatasetParts(tx, &rec, &record)
+	})
+	if err != nil {
+		status.Code, status.Error = bulkError(err)
+		return status
+	}
+	status.Status = BulkInserted
+	return status
+}
+
+// helper function to commit batch of records, if commit fails all records
+// of the batch which were inserted are marked as failed
+func commitBulk(tx *sql.Tx, batch []BulkStatus) []BulkStatus {
+	err := tx.Commit()
+	if err == nil {
+		return batch
+	}
+	log.Println("unable to commit bulk transaction", err)
+	tx.Rollback()
+	for idx, status := range batch {
+		if status.Status == BulkInserted {
+			batch[idx].Status = BulkFailed
+			batch[idx].Code, batch[idx].Error = CommitErrorCode, bulkMessage(err)
+		}
+	}
+	return batch
+}
+
+// helper function to get DBS error code and message of given error
+func bulkError(err error) (int, string) {
+	var e *DBSError
+	if errors.As(err, &e) {
+		msg := e.Reason
+		if e.Message != "" {
+			msg = fmt.Sprintf("%s: %s", e.Message, e.Reason)
+		}
+		return e.Code, bulkMessage(errors.New(msg))
+	}
+	return GenericErrorCode, bulkMessage(err)
+}
+
+// helper function to represent error as single line message
+func bulkMessage(err error) string {
+	return strings.Join(strings.Fields(err.Error()), " ")
+}
+
+// bulkReader reads dataset records from NDJSON or JSON list stream
+type bulkReader struct {
+	reader  *bufio.Reader
+	decoder *json.Decoder
+}
+
+// helper function to create bulk reader, the format of the stream is
+// determined by its first non-space character
+func newBulkReader(r io.Reader) (*bulkReader, error) {
+	reader := bufio.NewReader(r)
+	for {
+		b, err := reader.Peek(1)
+		if err == io.EOF {
+			return &bulkReader{reader: reader}, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if b[0] == ' ' || b[0] == '\t' || b[0] == '\r' || b[0] == '\n' {
+			reader.ReadByte()
+			continue
+		}
+		if b[0] != '[' {
+			return &bulkReader{reader: reader}, nil
+		}
+		decoder := json.NewDecoder(reader)
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return &bulkReader{decoder: decoder}, nil
+	}
+}
+
+// Next returns next record of the stream or io.EOF at the end of the stream
+func (b *bulkReader) Next() ([]byte, error) {
+	if b.decoder != nil {
+		if !b.decoder.More() {
+			if _, err := b.decoder.Token(); err != nil && err != io.EOF {
+				return nil, err
+			}
+			return nil, io.EOF
+		}
+		var data json.RawMessage
+		err := b.decoder.Decode(&data)
+		return data, err
+	}
+	for {
+		line, err := b.reader.ReadBytes('\n')
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 {
+			return line, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// bulkWriter writes bulk statuses as JSON list or NDJSON
+type bulkWriter struct {
+	Writer    io.Writer
+	Separator string
+	count     int
+}
+
+// Write writes given statuses to the writer
+func (w *bulkWriter) Write(statuses []BulkStatus) error {
+	for _, status := range statuses {
+		data, err := json.Marshal(status)
+		if err != nil {
+			return Error(err, MarshalErrorCode, "", "dbs.bulk.Write")
+		}
+		if w.Separator != "" {
+			if w.count == 0 {
+				data = append([]byte("[\n"), data...)
+			} else {
+				data = append([]byte(",\n"), data...)
+			}
+		} else {
+			data = append(data, '\n')
+		}
+		if _, err := w.Writer.Write(data); err != nil {
+			return Error(err, WriterErrorCode, "", "dbs.bulk.Write")
+		}
+		w.count++
+	}
+	return nil
+}
+
+// Close finalizes output of the writer
+func (w *bulkWriter) Close() error {
+	if w.Separator == "" {
+		return nil
+	}
+	end := "\n]\n"
+	if w.count == 0 {
+		end = "[]\n"
+	}
+	if _, err := w.Writer.Write([]byte(end)); err != nil {
+		return Error(err, WriterErrorCode, "", "dbs.bulk.Close")
+	}
+	return nil
+}
@@ -28,7 +28,7 @@ func (a *API) GetChild() error {
 	stm = WhereClause(stm, conds)
 
 	// use generic query API to fetch the results from DB
-	err = executeAll(a.Writer, a.Separator, stm, args...)
+	err = a.executePage(stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "fail to get child", "dbs.children.GetChild")
 	}
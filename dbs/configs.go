@@ -11,8 +11,10 @@ import (
 	"fmt"
 	"io"
 	"log"
-	"reflect"
+	"math"
+	"regexp"
 	"strconv"
+	"strings"
 )
 
 // Config represents Config DBS DB table
@@ -42,11 +44,15 @@ func (a *API) GetConfig() error {
 	if val, ok := a.Params["did"]; ok && val != "" {
 		conds, args = AddParam("did", "d.did", a.Params, conds, args)
 	}
+	conds, args, err = addFullTextParam("configs", "c", a.Params, conds, args)
+	if err != nil {
+		return Error(err, ParametersErrorCode, "", "dbs.config.Config")
+	}
 
 	stm = WhereClause(stm, conds)
 
 	// use generic query API to fetch the results from DB
-	err = executeAll(a.Writer, a.Separator, stm, args...)
+	err = a.executePage(stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "", "dbs.config.Config")
 	}
@@ -105,12 +111,12 @@ func (r *Config) Insert(tx *sql.Tx) (int64, error) {
 		}
 		r.CONFIG_ID = configID
 	}
-	// we need to convert content any type to a string for database insertion
-	if val, err := marshalForSQL(r.CONTENT); err == nil {
-		r.CONTENT = val
-	} else {
-		r.CONTENT = fmt.Sprintf("%v", r.CONTENT)
+	// content is stored as JSON document
+	content, err := marshalConfig(r.CONTENT)
+	if err != nil {
+		return 0, Error(err, MarshalErrorCode, "unable to marshal config content", "dbs.config.Insert")
 	}
+	r.CONTENT = content
 
 	// set defaults and validate the record
 	r.SetDefaults()
@@ -126,7 +132,8 @@ func (r *Config) Insert(tx *sql.Tx) (int64, error) {
 	} else if Verbose > 1 {
 		log.Printf("Insert Config\n%s\n%+v", stm, r)
 	}
-	_, err = tx.Exec(
+	r.CONFIG_ID, err = execInsert(
+		tx,
 		stm,
 		r.CONFIG_ID,
 		r.CONTENT,
@@ -165,34 +172,107 @@ func (r *Config) SetDefaults() {
 	}
 }
 
-// helper function to marshal given value to SQL string
-func marshalForSQL(val any) (string, error) {
+// helper function to marshal config content to JSON document, nil content
+// is stored as NULL
+func marshalConfig(val any) (any, error) {
 	if val == nil {
-		return "NULL", nil
+		return nil, nil
 	}
+	data, err := json.Marshal(val)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
 
-	// Handle string directly
-	switch v := val.(type) {
-	case string:
-		return strconv.Quote(v), nil // wrap in quotes
-	case fmt.Stringer:
-		return strconv.Quote(v.String()), nil
-	case int, int64, float64, bool:
-		return fmt.Sprint(v), nil // raw numbers/booleans
+// helper function to get value of stored config content, content which is
+// not valid JSON document is returned as is
+func configValue(content string) any {
+	if content == "" {
+		return nil
 	}
+	var val any
+	if err := json.Unmarshal([]byte(content), &val); err != nil {
+		return content
+	}
+	return val
+}
 
-	// For maps, slices, structs → JSON
-	rv := reflect.ValueOf(val)
-	kind := rv.Kind()
-	if kind == reflect.Map || kind == reflect.Slice || kind == reflect.Struct {
-		j, err := json.Marshal(val)
-		if err != nil {
-			msg := "unable to marshal record"
-			return "", Error(err, MarshalErrorCode, msg, "dbs.dbs.marshalForSQL")
-		}
-		return strconv.Quote(string(j)), nil // wrap JSON string in SQL quotes
+// pattern of keys of config path, e.g. detector, scan-1 or 0 (array index)
+var configKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_\-]+$`)
+
+// helper function to get typed value of config path condition, numbers and
+// booleans are compared as JSON numbers and booleans while NaN, infinity
+// and hexadecimal floats, which are not valid JSON numbers, remain strings
+func configPathValue(val string) any {
+	if v, err := strconv.ParseInt(val, 10, 64); err == nil {
+		return v
+	}
+	if v, err := strconv.ParseFloat(val, 64); err == nil &&
+		!math.IsNaN(v) && !math.IsInf(v, 0) && !strings.ContainsAny(val, "xX") {
+		return v
 	}
+	if v, err := strconv.ParseBool(val); err == nil && (val == "true" || val == "false") {
+		return v
+	}
+	return val
+}
 
-	// Fallback: convert to string
-	return strconv.Quote(fmt.Sprint(val)), nil
+// ConfigPathCondition provides SQL condition and its bind values which
+// match datasets whose config (aliased as c) has given value at given path,
+// e.g. config.detector.energy=12.5 matches config {"detector": {"energy": 12.5}}.
+// Numeric keys of the path refer to array elements and values with
+// wildcards are matched as text.
+func ConfigPathCondition(key, val string) (string, []interface{}, error) {
+	var args []interface{}
+	keys := strings.Split(strings.TrimPrefix(key, "config."), ".")
+	jpath := "$"
+	for _, k := range keys {
+		if !configKeyPattern.MatchString(k) {
+			msg := fmt.Sprintf("invalid config path %s", key)
+			return "", args, Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.ConfigPathCondition")
+		}
+		if _, err := strconv.Atoi(k); err == nil {
+			jpath += fmt.Sprintf("[%s]", k)
+		} else {
+			jpath += fmt.Sprintf(".\"%s\"", k)
+		}
+	}
+	op, value := OperatorValue(val)
+	like := op == "like"
+	typed := configPathValue(value)
+	doc, err := json.Marshal(typed)
+	if err != nil {
+		return "", args, Error(err, MarshalErrorCode, "", "dbs.ConfigPathCondition")
+	}
+	var cond string
+	switch DBOWNER {
+	case "sqlite":
+		cond = fmt.Sprintf(" json_extract(c.content, %s) = %s", placeholder("path"), placeholder("value"))
+		if like {
+			cond = fmt.Sprintf(" json_extract(c.content, %s) LIKE %s", placeholder("path"), placeholder("value"))
+			typed = value
+		}
+		args = append(args, jpath, typed)
+	case "mysql":
+		cond = fmt.Sprintf(" JSON_EXTRACT(c.content, %s) = CAST(%s AS JSON)", placeholder("path"), placeholder("value"))
+		if like {
+			cond = fmt.Sprintf(" JSON_UNQUOTE(JSON_EXTRACT(c.content, %s)) LIKE %s", placeholder("path"), placeholder("value"))
+			doc = []byte(value)
+		}
+		args = append(args, jpath, string(doc))
+	case "postgres":
+		path := fmt.Sprintf("{%s}", strings.Join(keys, ","))
+		cond = fmt.Sprintf(" c.content #> CAST(%s AS TEXT[]) = CAST(%s AS JSONB)", placeholder("path"), placeholder("value"))
+		if like {
+			cond = fmt.Sprintf(" c.content #>> CAST(%s AS TEXT[]) LIKE %s", placeholder("path"), placeholder("value"))
+			doc = []byte(value)
+		}
+		args = append(args, path, string(doc))
+	default:
+		// JSON path of ORACLE JSON_VALUE should be literal
+		cond = fmt.Sprintf(" JSON_VALUE(c.content, '%s') %s %s", jpath, strings.ToUpper(op), placeholder("value"))
+		args = append(args, value)
+	}
+	return cond, args, nil
 }
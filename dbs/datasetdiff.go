@@ -0,0 +1,474 @@
+package dbs
+
+// DBS dataset insertion modes and change report module
+//
+// nolint: gocyclo
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/CHESSComputing/golib/utils"
+)
+
+// DatasetModes lists supported modes of dataset insertion:
+// create fails if dataset already exists, upsert updates changed fields and
+// relationships of existing dataset and verify only reports the changes
+var DatasetModes = []string{"create", "upsert", "verify"}
+
+// statuses of dataset report
+const (
+	DatasetCreated   = "created"
+	DatasetUpdated   = "updated"
+	DatasetUnchanged = "unchanged"
+	DatasetDiffers   = "differs"
+)
+
+// dataset relations which are compared between stored and submitted records
+var datasetRelations = []string{"parent_dids", "environments", "scripts", "input_files", "output_files", "buckets"}
+
+// SQL templates which remove given relations of the dataset
+var datasetRelationsSQL = map[string]string{
+	"parent_dids":  "delete_dataset_parent",
+	"environments": "delete_dataset_environment",
+	"scripts":      "delete_dataset_script",
+	"input_files":  "delete_dataset_file",
+	"output_files": "delete_dataset_file",
+	"buckets":      "delete_dataset_bucket",
+}
+
+// attributes of relation elements which are compared between stored and
+// submitted records along with their names, e.g. version of environment
+var relationAttrs = map[string][]string{
+	"environments": {"version", "details"},
+	"scripts":      {"options", "order_idx"},
+	"input_files":  {"checksum", "size"},
+	"output_files": {"checksum", "size"},
+	"buckets":      {"uuid", "meta_data"},
+}
+
+// DatasetDiff represents difference of single field or relation between
+// stored and submitted dataset records
+type DatasetDiff struct {
+	Field     string           `json:"field"`
+	Stored    any              `json:"stored,omitempty"`
+	Submitted any              `json:"submitted,omitempty"`
+	Added     []string         `json:"added,omitempty"`
+	Removed   []string         `json:"removed,omitempty"`
+	Changed   []RelationChange `json:"changed,omitempty"`
+}
+
+// RelationChange represents changed attribute of relation element which
+// exists in both stored and submitted dataset records
+type RelationChange struct {
+	Name      string `json:"name"`
+	Attribute string `json:"attribute"`
+	Stored    string `json:"stored"`
+	Submitted string `json:"submitted"`
+}
+
+// DatasetReport represents report of dataset insertion in given mode
+type DatasetReport struct {
+	Did    string        `json:"did"`
+	Mode   string        `json:"mode"`
+	Status string        `json:"status"`
+	Diff   []DatasetDiff `json:"diff"`
+}
+
+// datasetState represents provenance attributes of dataset used for comparison
+type datasetState struct {
+	Site       string
+	Processing string
+	OsInfo     OsInfoRecord
+	Config     any
+	Status     string                                  // lifecycle status, it is not compared
+	Relations  map[string][]string                     // names of relation elements
+	Attrs      map[string]map[string]map[string]string // relation, name and attribute values
+}
+
+// helper function to add element of given relation and values of its
+// attributes (see relationAttrs) to dataset state
+func (s *datasetState) add(relation, name string, values ...string) {
+	s.Relations[relation] = append(s.Relations[relation], name)
+	if len(values) == 0 {
+		return
+	}
+	if s.Attrs == nil {
+		s.Attrs = make(map[string]map[string]map[string]string)
+	}
+	if s.Attrs[relation] == nil {
+		s.Attrs[relation] = make(map[string]map[string]string)
+	}
+	attrs := make(map[string]string)
+	for idx, attr := range relationAttrs[relation] {
+		if idx < len(values) {
+			attrs[attr] = values[idx]
+		}
+	}
+	s.Attrs[relation][name] = attrs
+}
+
+// helper function to build state of submitted dataset record
+func newDatasetState(rec *DatasetRecord) (datasetState, error) {
+	state := datasetState{
+		Site:       rec.Site,
+		Processing: rec.Processing,
+		OsInfo:     rec.OsInfo,
+		Relations:  make(map[string][]string),
+	}
+	// config is compared as JSON value, i.e. the way it is stored in DB
+	config, err := marshalConfig(rec.Config)
+	if err != nil {
+		return state, Error(err, MarshalErrorCode, "unable to marshal config", "dbs.datasetdiff.newDatasetState")
+	}
+	if content, ok := config.(string); ok {
+		state.Config = configValue(content)
+	}
+	state.Relations["parent_dids"] = rec.ParentDids()
+	for _, env := range rec.Environments {
+		if env.Name != "" {
+			state.add("environments", env.Name, env.Version, env.Details)
+		}
+	}
+	for _, script := range rec.Scripts {
+		if script.Name != "" {
+			state.add("scripts", script.Name, script.Options, fmt.Sprintf("%d", script.OrderIdx))
+		}
+	}
+	for _, f := range rec.InputFiles {
+		state.add("input_files", f.Name, fileAttrs(f)...)
+	}
+	for _, f := range rec.OutputFiles {
+		state.add("output_files", f.Name, fileAttrs(f)...)
+	}
+	for _, b := range rec.Buckets {
+		state.add("buckets", b.Name, b.UUID, b.MetaData)
+	}
+	return state, nil
+}
+
+// helper function to get file record of given relation from dataset state
+func (s *datasetState) fileRecord(relation, name string) FileRecord {
+	attrs := s.Attrs[relation][name]
+	size, _ := strconv.ParseInt(attrs["size"], 10, 64)
+	return FileRecord{Name: name, Checksum: attrs["checksum"], Size: size}
+}
+
+// helper function to get attributes of submitted file, checksum and size
+// are optional and they are compared only if provided
+func fileAttrs(f FileRecord) []string {
+	if f.Checksum == "" && f.Size == 0 {
+		return nil
+	}
+	return []string{f.Checksum, fmt.Sprintf("%d", f.Size)}
+}
+
+// helper function to load state of stored dataset
+func loadDatasetState(tx *sql.Tx, datasetId int64) (datasetState, error) {
+	state := datasetState{Relations: make(map[string][]string)}
+	var site, processing, osName, osVersion, osKernel, status sql.NullString
+	err := tx.QueryRow(getSQL("select_dataset_state"), datasetId).Scan(
+		&site, &processing, &osName, &osVersion, &osKernel, &status)
+	if err != nil {
+		return state, Error(err, QueryErrorCode, "", "dbs.datasetdiff.loadDatasetState")
+	}
+	state.Site = site.String
+	state.Processing = processing.String
+	state.Status = status.String
+	state.OsInfo = OsInfoRecord{Name: osName.String, Version: osVersion.String, Kernel: osKernel.String}
+
+	// config is JSON document, therefore it is not part of relations query
+	var config sql.NullString
+	err = tx.QueryRow(getSQL("select_dataset_config"), datasetId).Scan(&config)
+	if err != nil && err != sql.ErrNoRows {
+		return state, Error(err, QueryErrorCode, "", "dbs.datasetdiff.loadDatasetState")
+	}
+	if config.Valid {
+		state.Config = configValue(config.String)
+	}
+
+	stm := getSQL("select_dataset_relations")
+	args := []interface{}{datasetId, datasetId, datasetId, datasetId, datasetId}
+	rows, err := tx.Query(stm, args...)
+	if err != nil {
+		return state, Error(err, QueryErrorCode, "", "dbs.datasetdiff.loadDatasetState")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var relation string
+		var name, attr, textAttr sql.NullString
+		var numAttr sql.NullInt64
+		if err := rows.Scan(&relation, &name, &attr, &textAttr, &numAttr); err != nil {
+			return state, Error(err, RowsScanErrorCode, "", "dbs.datasetdiff.loadDatasetState")
+		}
+		num := fmt.Sprintf("%d", numAttr.Int64)
+		switch relation {
+		case "parent_dids":
+			state.add(relation, name.String)
+		case "environments", "buckets":
+			state.add(relation, name.String, attr.String, textAttr.String)
+		case "input", "output":
+			state.add(relation+"_files", name.String, attr.String, num)
+		default:
+			state.add(relation, name.String, attr.String, num)
+		}
+	}
+	return state, rows.Err()
+}
+
+// Diff provides list of differences between stored and submitted states
+func (s *datasetState) Diff(submitted datasetState) []DatasetDiff {
+	diffs := []DatasetDiff{}
+	if s.Site != submitted.Site {
+		diffs = append(diffs, DatasetDiff{Field: "site", Stored: s.Site, Submitted: submitted.Site})
+	}
+	if s.Processing != submitted.Processing {
+		diffs = append(diffs, DatasetDiff{Field: "processing", Stored: s.Processing, Submitted: submitted.Processing})
+	}
+	if s.OsInfo != submitted.OsInfo {
+		diffs = append(diffs, DatasetDiff{Field: "osinfo", Stored: s.OsInfo, Submitted: submitted.OsInfo})
+	}
+	if !reflect.DeepEqual(s.Config, submitted.Config) {
+		diffs = append(diffs, DatasetDiff{Field: "config", Stored: s.Config, Submitted: submitted.Config})
+	}
+	for _, relation := range datasetRelations {
+		added := listDifference(submitted.Relations[relation], s.Relations[relation])
+		removed := listDifference(s.Relations[relation], submitted.Relations[relation])
+		changed := s.changes(submitted, relation)
+		if len(added) > 0 || len(removed) > 0 || len(changed) > 0 {
+			diffs = append(diffs, DatasetDiff{Field: relation, Added: added, Removed: removed, Changed: changed})
+		}
+	}
+	return diffs
+}
+
+// helper function to get changed attributes of relation elements which
+// exist in both stored and submitted states, attributes which are not
+// provided in submitted state are not compared
+func (s *datasetState) changes(submitted datasetState, relation string) []RelationChange {
+	var changes []RelationChange
+	names := UniqueList(submitted.Relations[relation])
+	sort.Strings(names)
+	for _, name := range names {
+		stored, ok := s.Attrs[relation][name]
+		if !ok {
+			continue
+		}
+		attrs := submitted.Attrs[relation][name]
+		for _, attr := range relationAttrs[relation] {
+			val, ok := attrs[attr]
+			if ok && val != stored[attr] {
+				changes = append(changes, RelationChange{
+					Name: name, Attribute: attr, Stored: stored[attr], Submitted: val})
+			}
+		}
+	}
+	return changes
+}
+
+// helper function to get sorted list of unique elements of a which are not in b
+func listDifference(a, b []string) []string {
+	var out []string
+	for _, v := range UniqueList(a) {
+		if !utils.InList(v, b) {
+			out = append(out, v)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// helper function to insert dataset record in given mode and report its changes
+func insertDatasetMode(rec *DatasetRecord, record *Datasets, mode string) (DatasetReport, error) {
+	report := DatasetReport{Did: rec.Did, Mode: mode, Diff: []DatasetDiff{}}
+	tx, err := DB.Begin()
+	if err != nil {
+		return report, Error(err, TransactionErrorCode, "", "dbs.datasetdiff.insertDatasetMode")
+	}
+	defer tx.Rollback()
+
+	datasetId, err := GetID(tx, "datasets", "dataset_id", "did", rec.Did)
+	if err != nil || datasetId == 0 {
+		if mode == "verify" {
+			msg := fmt.Sprintf("dataset %s is not found", rec.Did)
+			return report, Error(RecordErr, NoDataErrorCode, msg, "dbs.datasetdiff.insertDatasetMode")
+		}
+		if err := insertDatasetParts(tx, rec, record); err != nil {
+			return report, err
+		}
+		if err := tx.Commit(); err != nil {
+			return report, Error(err, CommitErrorCode, "", "dbs.datasetdiff.insertDatasetMode")
+		}
+		report.Status = DatasetCreated
+		return report, nil
+	}
+	if mode == "create" {
+		msg := fmt.Sprintf("dataset %s already exists", rec.Did)
+		return report, Error(RecordErr, DatasetErrorCode, msg, "dbs.datasetdiff.insertDatasetMode")
+	}
+
+	stored, err := loadDatasetState(tx, datasetId)
+	if err != nil {
+		return report, err
+	}
+	submitted, err := newDatasetState(rec)
+	if err != nil {
+		return report, err
+	}
+	report.Diff = stored.Diff(submitted)
+	if len(report.Diff) == 0 {
+		report.Status = DatasetUnchanged
+		return report, nil
+	}
+	if mode == "verify" {
+		report.Status = DatasetDiffers
+		return report, nil
+	}
+
+	record.DATASET_ID = datasetId
+	if err := updateDatasetParts(tx, rec, record, report.Diff); err != nil {
+		return report, err
+	}
+	if err := tx.Commit(); err != nil {
+		return report, Error(err, CommitErrorCode, "", "dbs.datasetdiff.insertDatasetMode")
+	}
+	report.Status = DatasetUpdated
+	return report, nil
+}
+
+// helper function to update fields and relationships of existing dataset
+// which differ from submitted record
+func updateDatasetParts(tx *sql.Tx, rec *DatasetRecord, record *Datasets, diffs []DatasetDiff) error {
+	datasetId := record.DATASET_ID
+	if err := snapshotDataset(tx, datasetId, rec.Did, record.MODIFY_BY); err != nil {
+		return err
+	}
+	configChanged := false
+	for _, diff := range diffs {
+		if diff.Field == "config" {
+			configChanged = true
+		}
+		if tmpl, ok := datasetRelationsSQL[diff.Field]; ok {
+			if _, err := DeleteManyToMany(tx, tmpl, datasetId); err != nil {
+				return err
+			}
+		}
+	}
+
+	// insert relationships of submitted record, existing ones are kept as is
+	if err := insertDatasetParts(tx, rec, record); err != nil {
+		return err
+	}
+	if err := updateRelationRecords(tx, rec, datasetId, record.MODIFY_BY, diffs); err != nil {
+		return err
+	}
+
+	// os info is looked-up by all its attributes to catch version changes
+	osId, err := osInfoID(tx, rec.OsInfo)
+	if err != nil {
+		return err
+	}
+	record.OSINFO_ID = osId
+	record.MODIFY_AT = Date()
+	if err := record.Update(tx); err != nil {
+		return Error(err, UpdateErrorCode, "unable to update dataset", "dbs.datasetdiff.updateDatasetParts")
+	}
+
+	if configChanged {
+		if _, err := DeleteManyToMany(tx, "delete_dataset_config", datasetId); err != nil {
+			return err
+		}
+		config := Config{CONTENT: rec.Config}
+		configId, err := config.Insert(tx)
+		if err != nil {
+			return Error(err, InsertErrorCode, "unable to insert config", "dbs.datasetdiff.updateDatasetParts")
+		}
+		if err := InsertManyToMany(tx, "insert_dataset_config", datasetId, configId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// helper function to update attributes of environments, scripts and files
+// which differ from submitted record. These records are identified by their
+// names and may be shared among datasets, therefore only records used solely
+// by given dataset are updated in place while changes of shared ones are
+// rejected since they would alter provenance of other datasets. Buckets of
+// the dataset are re-inserted along with other relationships.
+func updateRelationRecords(tx *sql.Tx, rec *DatasetRecord, datasetId int64, modifyBy string, diffs []DatasetDiff) error {
+	changed := make(map[string]bool)
+	for _, diff := range diffs {
+		for _, change := range diff.Changed {
+			changed[diff.Field+":"+change.Name] = true
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	tstamp := Date()
+	for _, env := range rec.Environments {
+		if changed["environments:"+env.Name] {
+			if err := checkSharedRecord(tx, "select_environment_datasets", "environment", env.Name, datasetId); err != nil {
+				return err
+			}
+			_, err := tx.Exec(getSQL("update_environment_record"),
+				env.Version, env.Details, tstamp, modifyBy, env.Name)
+			if err != nil {
+				return Error(err, UpdateErrorCode, "unable to update environment "+env.Name, "dbs.datasetdiff.updateRelationRecords")
+			}
+		}
+	}
+	for _, script := range rec.Scripts {
+		if changed["scripts:"+script.Name] {
+			if err := checkSharedRecord(tx, "select_script_datasets", "script", script.Name, datasetId); err != nil {
+				return err
+			}
+			_, err := tx.Exec(getSQL("update_script_record"),
+				script.Options, script.OrderIdx, tstamp, modifyBy, script.Name)
+			if err != nil {
+				return Error(err, UpdateErrorCode, "unable to update script "+script.Name, "dbs.datasetdiff.updateRelationRecords")
+			}
+		}
+	}
+	files := append(append([]FileRecord{}, rec.InputFiles...), rec.OutputFiles...)
+	for _, f := range files {
+		if changed["input_files:"+f.Name] || changed["output_files:"+f.Name] {
+			if err := checkSharedRecord(tx, "select_file_datasets", "file", f.Name, datasetId); err != nil {
+				return err
+			}
+			_, err := tx.Exec(getSQL("update_file_record"),
+				f.Checksum, f.Size, tstamp, modifyBy, f.Name)
+			if err != nil {
+				return Error(err, UpdateErrorCode, "unable to update file "+f.Name, "dbs.datasetdiff.updateRelationRecords")
+			}
+		}
+	}
+	return nil
+}
+
+// helper function to check that record of given kind and name is not used by
+// datasets other than given one, i.e. its attributes can be updated in place
+func checkSharedRecord(tx *sql.Tx, tmpl, kind, name string, datasetId int64) error {
+	var count int64
+	if err := tx.QueryRow(getSQL(tmpl), name, datasetId).Scan(&count); err != nil {
+		return Error(err, QueryErrorCode, "", "dbs.datasetdiff.checkSharedRecord")
+	}
+	if count > 0 {
+		msg := fmt.Sprintf(
+			"%s %s is used by %d other dataset(s), its attributes can not be changed",
+			kind, name, count)
+		return Error(InvalidRequestErr, InvalidRequestErrorCode, msg, "dbs.datasetdiff.checkSharedRecord")
+	}
+	return nil
+}
+
+// helper function to get id of os info matching all its attributes,
+// the os info record is inserted if it does not exist
+func osInfoID(tx *sql.Tx, rec OsInfoRecord) (int64, error) {
+	attrs := []string{"name", "version", "kernel"}
+	return GetRecIDMulti(tx, &rec, "osinfo", "os_id", attrs, rec.Name, rec.Version, rec.Kernel)
+}
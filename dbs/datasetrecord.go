@@ -12,6 +12,7 @@ type DatasetRecord struct {
 	Site         string              `json:"site" validate:"required"`
 	Processing   string              `json:"processing" validate:"required"`
 	Parent       string              `json:"parent_did" validate:"required"`
+	Parents      []string            `json:"parent_dids,omitempty"`
 	InputFiles   []FileRecord        `json:"input_files,omitempty" validate:"required"`
 	OutputFiles  []FileRecord        `json:"output_files,omitempty" validate:"required"`
 	Environments []EnvironmentRecord `json:"environments"`
@@ -19,6 +20,12 @@ type DatasetRecord struct {
 	Buckets      []BucketRecord      `json:"buckets"`
 	OsInfo       OsInfoRecord        `json:"osinfo"`
 	Config       any                 `json:"config"`
+	Status       string              `json:"status,omitempty"` // lifecycle status kept in dataset history
+}
+
+// ParentDids returns unique list of parent dids of the record
+func (r *DatasetRecord) ParentDids() []string {
+	return parentDids(r.Parent, r.Parents)
 }
 
 // Validate implementation of DatasetRecord
@@ -35,9 +42,11 @@ func (r *DatasetRecord) Validate() error {
 		msg := fmt.Sprintf("fail processing validation: '%v'", r.Processing)
 		return Error(err, ValidateErrorCode, msg, "dbs.DatasetRecord.Validate")
 	}
-	if err := lexicon.CheckPattern("dataset_parent", r.Parent); err != nil {
-		msg := fmt.Sprintf("fail parent validation: '%v'", r.Parent)
-		return Error(err, ValidateErrorCode, msg, "dbs.DatasetRecord.Validate")
+	for _, parent := range r.ParentDids() {
+		if err := lexicon.CheckPattern("dataset_parent", parent); err != nil {
+			msg := fmt.Sprintf("fail parent validation: '%v'", parent)
+			return Error(err, ValidateErrorCode, msg, "dbs.DatasetRecord.Validate")
+		}
 	}
 	for _, b := range r.Buckets {
 		if err := lexicon.CheckPattern("bucket", b.Name); err != nil {
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"sort"
 	"strings"
 
 	lexicon "github.com/CHESSComputing/golib/lexicon"
@@ -39,11 +40,19 @@ func (a *API) GetDataset() error {
 	}
 	var args []interface{}
 	var conds []string
+	var err error
 	tmpl := make(map[string]any)
 	tmpl["Owner"] = DBOWNER
 
-	allowed := []string{"did", "file", "script", "environment", "package", "site", "bucket", "osname", "processing", "config"}
+	allowed := []string{"did", "file", "script", "environment", "package", "site", "bucket", "osname", "processing", "config", "status"}
+	allowed = append(allowed, TimeFilters...)
+	allowed = append(allowed, PaginationKeys...)
+	var configKeys []string
 	for k, _ := range a.Params {
+		if strings.HasPrefix(k, "config.") {
+			configKeys = append(configKeys, k)
+			continue
+		}
 		if !utils.InList(k, allowed) {
 			msg := fmt.Sprintf("invalid parameter %s", k)
 			return errors.New(msg)
@@ -56,28 +65,38 @@ func (a *API) GetDataset() error {
 		conds, args = AddParam("did", "d.did", a.Params, conds, args)
 	}
 	if val, ok := a.Params["file"]; ok && val != "" {
-		conds, args = AddParam("file", "f.file", a.Params, conds, args)
-		tmpl["Files"] = true
+		conds, args, err = relationParam("file", "f.file", "Files", a.Params, conds, args, tmpl)
+		if err != nil {
+			return Error(err, ParametersErrorCode, "", "dbs.datasets.Datasets")
+		}
 	}
 	if val, ok := a.Params["script"]; ok && val != "" {
-		conds, args = AddParam("script", "sc.name", a.Params, conds, args)
-		tmpl["Scripts"] = true
+		conds, args, err = relationParam("script", "sc.name", "Scripts", a.Params, conds, args, tmpl)
+		if err != nil {
+			return Error(err, ParametersErrorCode, "", "dbs.datasets.Datasets")
+		}
 	}
 	if val, ok := a.Params["environment"]; ok && val != "" {
-		conds, args = AddParam("environment", "e.name", a.Params, conds, args)
-		tmpl["Environments"] = true
+		conds, args, err = relationParam("environment", "e.name", "Environments", a.Params, conds, args, tmpl)
+		if err != nil {
+			return Error(err, ParametersErrorCode, "", "dbs.datasets.Datasets")
+		}
 	}
 	if val, ok := a.Params["package"]; ok && val != "" {
-		conds, args = AddParam("package", "ep.name", a.Params, conds, args)
-		tmpl["Environments"] = true
+		conds, args, err = relationParam("package", "pk.name", "Environments", a.Params, conds, args, tmpl)
+		if err != nil {
+			return Error(err, ParametersErrorCode, "", "dbs.datasets.Datasets")
+		}
 	}
 	if val, ok := a.Params["site"]; ok && val != "" {
 		conds, args = AddParam("site", "s.site", a.Params, conds, args)
 		tmpl["Sites"] = true
 	}
 	if val, ok := a.Params["bucket"]; ok && val != "" {
-		conds, args = AddParam("bucket", "b.bucket", a.Params, conds, args)
-		tmpl["Buckets"] = true
+		conds, args, err = relationParam("bucket", "b.bucket", "Buckets", a.Params, conds, args, tmpl)
+		if err != nil {
+			return Error(err, ParametersErrorCode, "", "dbs.datasets.Datasets")
+		}
 	}
 	if val, ok := a.Params["processing"]; ok && val != "" {
 		conds, args = AddParam("processing", "pr.processing", a.Params, conds, args)
@@ -87,6 +106,37 @@ func (a *API) GetDataset() error {
 		conds, args = AddParam("osname", "o.name", a.Params, conds, args)
 		tmpl["Osinfo"] = true
 	}
+	// conditions on values of config documents, e.g. config.detector.energy=12.5
+	sort.Strings(configKeys)
+	for _, key := range configKeys {
+		val, err := getSingleValue(a.Params, key)
+		if err != nil {
+			return Error(err, ParametersErrorCode, "", "dbs.datasets.Datasets")
+		}
+		cond, vals, err := ConfigPathCondition(key, val)
+		if err != nil {
+			return Error(err, ParametersErrorCode, "", "dbs.datasets.Datasets")
+		}
+		conds = append(conds, cond)
+		args = append(args, vals...)
+		tmpl["Config"] = true
+	}
+	conds, args, err = AddTimeFilters("d", a.Params, conds, args)
+	if err != nil {
+		return Error(err, ParametersErrorCode, "", "dbs.datasets.Datasets")
+	}
+	// invalid datasets are hidden unless status is explicitly requested
+	if vals := getValues(a.Params, "status"); len(vals) > 0 && vals[0] != "" {
+		var statuses []string
+		for _, val := range vals {
+			statuses = append(statuses, strings.ToUpper(val))
+		}
+		a.Params["status"] = statuses
+		conds, args = AddParam("status", "d.status", a.Params, conds, args)
+	} else {
+		conds = append(conds, fmt.Sprintf(" d.status <> %s", placeholder("status")))
+		args = append(args, DatasetInvalid)
+	}
 
 	// get SQL statement from static area
 	stm, err := LoadTemplateSQL("select_dataset", tmpl)
@@ -96,13 +146,54 @@ func (a *API) GetDataset() error {
 	stm = WhereClause(stm, conds)
 
 	// use generic query API to fetch the results from DB
-	err = executeAll(a.Writer, a.Separator, stm, args...)
+	err = a.executePage(stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "", "dbs.datasets.Datasets")
 	}
 	return nil
 }
 
+// helper function to add conditions of query parameter of dataset relation
+// which may have many records per dataset, e.g. files or scripts. Positive
+// values are matched against joined records while negated ones are matched
+// via sub-query of dataset ids, otherwise dataset would still match through
+// its other records, e.g. file=!x should exclude datasets which have file x.
+func relationParam(
+	name, sqlName, join string,
+	params map[string]any,
+	conds []string,
+	args []interface{},
+	tmpl map[string]any) ([]string, []interface{}, error) {
+
+	var values, negated []string
+	for _, val := range getValues(params, name) {
+		if strings.HasPrefix(val, "!") {
+			negated = append(negated, paramValue(strings.TrimPrefix(val, "!")))
+		} else {
+			values = append(values, val)
+		}
+	}
+	if len(values) > 0 {
+		conds, args = AddParam(name, sqlName, map[string]any{name: values}, conds, args)
+		tmpl[join] = true
+	}
+	if len(negated) > 0 {
+		stmpl := make(map[string]any)
+		stmpl["Owner"] = DBOWNER
+		stmpl["Ids"] = true
+		stmpl[join] = true
+		stm, err := LoadTemplateSQL("select_dataset", stmpl)
+		if err != nil {
+			return conds, args, Error(err, LoadErrorCode, "", "dbs.relationParam")
+		}
+		// negated values use their own bind names, see placeholder
+		cond, vals := valuesCondition(name+"_not", sqlName, negated, false)
+		conds = append(conds, fmt.Sprintf(" d.dataset_id NOT IN (%s)", WhereClause(stm, []string{cond})))
+		args = append(args, vals...)
+	}
+	return conds, args, nil
+}
+
 // InsertDataset inserts dataset into database
 func (a *API) InsertDataset() error {
 	// the API provides Reader which will be used by Decode function to load the HTTP payload
@@ -145,6 +236,25 @@ func (a *API) InsertDataset() error {
 	if err != nil {
 		return Error(err, ValidateErrorCode, "validation error", "dbs.datasets.InsertDataset")
 	}
+
+	// explicit insertion mode provides report of dataset changes
+	if _, ok := a.Params["mode"]; ok {
+		mode, err := getSingleValue(a.Params, "mode")
+		if err != nil || !utils.InList(mode, DatasetModes) {
+			msg := fmt.Sprintf("invalid mode '%s', supported modes: %v", mode, DatasetModes)
+			return Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.datasets.InsertDataset")
+		}
+		report, err := insertDatasetMode(&rec, &record, mode)
+		if err != nil {
+			return Error(err, DatasetErrorCode, "fail to insert dataset in "+mode+" mode", "dbs.datasets.InsertDataset")
+		}
+		data, err := json.Marshal([]DatasetReport{report})
+		if err != nil {
+			return Error(err, MarshalErrorCode, "", "dbs.datasets.InsertDataset")
+		}
+		a.Writer.Write(data)
+		return nil
+	}
 	err = insertParts(&rec, &record)
 	if err != nil {
 		return Error(err, DatasetErrorCode, "fail to insert parts of dataset", "dbs.insertRecord")
@@ -160,6 +270,23 @@ func insertParts(rec *DatasetRecord, record *Datasets) error {
 		return Error(err, TransactionErrorCode, "", "dbs.insertRecord")
 	}
 	defer tx.Rollback()
+	err = insertDatasetParts(tx, rec, record)
+	if err != nil {
+		return err
+	}
+
+	// commit all transactions
+	err = tx.Commit()
+	if err != nil {
+		msg := "unable to commit transaction"
+		return Error(err, CommitErrorCode, msg, "dbs.insertParts")
+	}
+	return nil
+}
+
+// helper function to insert dataset and its relationships within given transaction
+func insertDatasetParts(tx *sql.Tx, rec *DatasetRecord, record *Datasets) error {
+	var err error
 	var siteId, processingId, datasetId, osId, scriptId, fileId, bucketId, configId int64
 	var envIds, scriptIds []int64
 
@@ -173,14 +300,11 @@ func insertParts(rec *DatasetRecord, record *Datasets) error {
 		if Verbose > 0 {
 			log.Printf("insert/look-up rec.Site %+v", rec.Site)
 		}
-		siteId, err = GetID(tx, "sites", "site_id", "site", rec.Site)
-		if err != nil || siteId == 0 {
-			site := Sites{SITE: rec.Site}
-			siteId, err = site.Insert(tx)
-			if err != nil {
-				msg := "unable to insert site record"
-				return Error(err, InsertErrorCode, msg, "dbs.insertParts")
-			}
+		site := Sites{SITE: rec.Site}
+		siteId, err = GetRecID(tx, &site, "sites", "site_id", "site", rec.Site)
+		if err != nil {
+			msg := "unable to insert site record"
+			return Error(err, InsertErrorCode, msg, "dbs.insertParts")
 		}
 	}
 	record.SITE_ID = siteId
@@ -190,13 +314,10 @@ func insertParts(rec *DatasetRecord, record *Datasets) error {
 		if Verbose > 0 {
 			log.Printf("insert/look-up rec.OsInfo %+v", rec.OsInfo)
 		}
-		osId, err = GetID(tx, "osinfo", "os_id", "name", rec.OsInfo.Name)
-		if err != nil || osId == 0 {
-			osId, err = rec.OsInfo.Insert(tx)
-			if err != nil {
-				msg := "unable to insert os info record"
-				return Error(err, InsertErrorCode, msg, "dbs.insertParts")
-			}
+		osId, err = osInfoID(tx, rec.OsInfo)
+		if err != nil {
+			msg := "unable to insert os info record"
+			return Error(err, InsertErrorCode, msg, "dbs.insertParts")
 		}
 		record.OSINFO_ID = osId
 	} else {
@@ -210,13 +331,10 @@ func insertParts(rec *DatasetRecord, record *Datasets) error {
 			if Verbose > 0 {
 				log.Printf("insert/look-up record environement %+v", env)
 			}
-			environmentId, err := GetID(tx, "environments", "environment_id", "name", env.Name)
-			if err != nil || environmentId == 0 {
-				environmentId, err = env.Insert(tx)
-				if err != nil {
-					msg := "unable to insert environment record"
-					return Error(err, InsertErrorCode, msg, "dbs.insertParts")
-				}
+			environmentId, err := GetRecID(tx, &env, "environments", "environment_id", "name", env.Name)
+			if err != nil {
+				msg := "unable to insert environment record"
+				return Error(err, InsertErrorCode, msg, "dbs.insertParts")
 			}
 			if environmentId == 0 {
 				msg := fmt.Sprintf("unable to obtain environment id for %s", env.Name)
@@ -232,13 +350,10 @@ func insertParts(rec *DatasetRecord, record *Datasets) error {
 			if Verbose > 0 {
 				log.Printf("insert/look-up record script %+v", script)
 			}
-			scriptId, err = GetID(tx, "scripts", "script_id", "name", script.Name)
-			if err != nil || scriptId == 0 {
-				scriptId, err = script.Insert(tx)
-				if err != nil && !strings.Contains(err.Error(), "UNIQUE") {
-					msg := "unable to insert scripts record"
-					return Error(err, InsertErrorCode, msg, "dbs.insertParts")
-				}
+			scriptId, err = GetRecID(tx, &script, "scripts", "script_id", "name", script.Name)
+			if err != nil {
+				msg := "unable to insert scripts record"
+				return Error(err, InsertErrorCode, msg, "dbs.insertParts")
 			}
 			scriptIds = append(scriptIds, scriptId)
 		} else {
@@ -255,22 +370,21 @@ func insertParts(rec *DatasetRecord, record *Datasets) error {
 	if Verbose > 0 {
 		log.Printf("insert/look-up record processing %+v", rec.Processing)
 	}
-	processingId, err = GetID(tx, "processing", "processing_id", "processing", rec.Processing)
-	if err != nil || processingId == 0 {
-		processing := Processing{
-			PROCESSING: rec.Processing,
-		}
-		processingId, err = processing.Insert(tx)
-		if err != nil {
-			msg := "unable to unsert processing record"
-			return Error(err, InsertErrorCode, msg, "dbs.insertParts")
-		}
+	processing := Processing{
+		PROCESSING: rec.Processing,
+	}
+	processingId, err = GetRecID(tx, &processing, "processing", "processing_id", "processing", rec.Processing)
+	if err != nil {
+		msg := "unable to unsert processing record"
+		return Error(err, InsertErrorCode, msg, "dbs.insertParts")
 	}
 	record.PROCESSING_ID = processingId
 
 	// insert dataset info
+	created := false
 	datasetId, err = GetID(tx, "datasets", "dataset_id", "did", rec.Did)
 	if err != nil {
+		created = true
 		if Verbose > 0 {
 			log.Printf("insert/look-up record dataset %+v", rec.Did)
 		}
@@ -287,53 +401,41 @@ func insertParts(rec *DatasetRecord, record *Datasets) error {
 	// insert dataset-environments relationships
 	for _, envId := range envIds {
 		err = InsertManyToMany(tx, "insert_dataset_environment", datasetId, envId)
-		if err != nil && !strings.Contains(err.Error(), "UNIQUE") {
-			msg := "unable to insert dataset environment, UNIQUE constrain violation"
+		if err != nil && !isUniqueViolation(err) {
+			msg := "unable to insert dataset environment"
 			return Error(err, InsertErrorCode, msg, "dbs.insertParts")
 		}
 	}
 	// insert dataset-scripts relationships
 	for _, sid := range scriptIds {
 		err = InsertManyToMany(tx, "insert_dataset_script", datasetId, sid)
-		if err != nil && !strings.Contains(err.Error(), "UNIQUE") {
-			msg := "unable to insert dataset script, UNIQUE constrain violation"
-			Error(err, InsertErrorCode, msg, "dbs.insertParts")
+		if err != nil && !isUniqueViolation(err) {
+			msg := "unable to insert dataset script"
+			return Error(err, InsertErrorCode, msg, "dbs.insertParts")
 		}
 	}
-	// insert dataset-configs relationships
-	config := Config{CONTENT: rec.Config}
-	configId, err = config.Insert(tx)
-	err = InsertManyToMany(tx, "insert_dataset_config", datasetId, configId)
-	if err != nil && !strings.Contains(err.Error(), "UNIQUE") {
-		msg := "unable o insert dataset config, UNIQUE constrain violation"
-		return Error(err, InsertErrorCode, msg, "dbs.insertParts")
-	}
-	record.CONFIG_ID = configId
-
-	// insert parent info
-	if rec.Parent != "" {
-		// look-up parent_id for given did name (rec.Parent)
-		parentId, err := GetID(tx, "datasets", "dataset_id", "did", rec.Parent)
+	// insert dataset-configs relationships, config of existing dataset
+	// can only be changed via upsert mode
+	if created {
+		config := Config{CONTENT: rec.Config}
+		configId, err = config.Insert(tx)
 		if err != nil {
-			// if we didn't find dataset id we will insert it
-			ds := Datasets{DID: rec.Parent}
-			parentId, err = ds.Insert(tx)
-			if err != nil {
-				msg := "unable to insert dataset record with parent"
-				return Error(err, InsertErrorCode, msg, "dbs.insertParts")
-			}
-		}
-		if parentId == 0 {
-			msg := fmt.Sprintf("no dataset id found for did %s", rec.Parent)
-			return errors.New(msg)
+			msg := "unable to insert config"
+			return Error(err, InsertErrorCode, msg, "dbs.insertParts")
 		}
-		// insert relation between parent id and dataset id
-		parent := Parents{PARENT_ID: parentId, DATASET_ID: datasetId}
-		_, err = parent.Insert(tx)
-		if err != nil {
-			msg := "unable to insert parents record"
+		err = InsertManyToMany(tx, "insert_dataset_config", datasetId, configId)
+		if err != nil && !isUniqueViolation(err) {
+			msg := "unable to insert dataset config"
 			return Error(err, InsertErrorCode, msg, "dbs.insertParts")
 		}
+		record.CONFIG_ID = configId
+	}
+
+	// insert parent info, all parents should be present in datasets table
+	err = insertParents(tx, datasetId, rec.Did, rec.ParentDids(), record.CREATE_BY)
+	if err != nil {
+		msg := "unable to insert parents record"
+		return Error(err, InsertErrorCode, msg, "dbs.insertParts")
 	}
 
 	// insert all buckets
@@ -354,50 +456,31 @@ func insertParts(rec *DatasetRecord, record *Datasets) error {
 
 	// insert all input files
 	for _, f := range rec.InputFiles {
-		fileId, err = GetID(tx, "files", "file_id", "file", f.Name)
+		fileId, err = GetRecID(tx, &f, "files", "file_id", "file", f.Name)
 		if err != nil {
-			fileId, err = f.Insert(tx)
-			if err != nil {
-				log.Printf("Fail to insert %+v, error %v ", f, err)
-			}
-		}
-		if fileId == 0 {
-			msg := fmt.Sprintf("unable to find input file %+v", f)
-			return errors.New(msg)
+			msg := fmt.Sprintf("unable to insert input file %+v", f)
+			return Error(err, InsertErrorCode, msg, "dbs.insertParts")
 		}
 		err = InsertManyToMany(tx, "insert_dataset_file", datasetId, fileId, "input")
-		if err != nil && !strings.Contains(err.Error(), "UNIQUE") {
-			msg := "unable to insert dataset file, UNIQUE constrain violation"
+		if err != nil && !isUniqueViolation(err) {
+			msg := "unable to insert dataset file"
 			return Error(err, InsertErrorCode, msg, "dbs.insertParts")
 		}
 	}
 
 	// insert all output files
 	for _, f := range rec.OutputFiles {
-		fileId, err = GetID(tx, "files", "file_id", "file", f.Name)
+		fileId, err = GetRecID(tx, &f, "files", "file_id", "file", f.Name)
 		if err != nil {
-			fileId, err = f.Insert(tx)
-			if err != nil {
-				log.Printf("Fail to insert %+v, error %v ", f, err)
-			}
-		}
-		if fileId == 0 {
-			msg := fmt.Sprintf("unable to find output file %+v", f)
-			return errors.New(msg)
+			msg := fmt.Sprintf("unable to insert output file %+v", f)
+			return Error(err, InsertErrorCode, msg, "dbs.insertParts")
 		}
 		err = InsertManyToMany(tx, "insert_dataset_file", datasetId, fileId, "output")
-		if err != nil && !strings.Contains(err.Error(), "UNIQUE") {
-			msg := "unable to insert dataset file, UNIQUE constrain violation"
+		if err != nil && !isUniqueViolation(err) {
+			msg := "unable to insert dataset file"
 			return Error(err, InsertErrorCode, msg, "dbs.insertParts")
 		}
 	}
-
-	// commit all transactions
-	err = tx.Commit()
-	if err != nil {
-		msg := "unable to commit transaction"
-		return Error(err, CommitErrorCode, msg, "dbs.insertParts")
-	}
 	return nil
 }
 
@@ -409,23 +492,213 @@ func (a *API) UpdateDataset() error {
 		return Error(err, ReaderErrorCode, msg, "dbs.API.UpdateDataset")
 	}
 	rec := &Datasets{}
-	return DBOperation("update", rec, data, "dbs.UpdateDatset")
+	err = json.Unmarshal(data, rec)
+	if err != nil {
+		return Error(err, UnmarshalErrorCode, "fail to unmarshal payload data", "dbs.API.UpdateDataset")
+	}
+	// modification attributes are set by the server
+	rec.MODIFY_AT = Date()
+	rec.MODIFY_BY = a.CreateBy
+	rec.SetDefaults()
+	err = rec.Validate()
+	if err != nil {
+		return Error(err, ValidateErrorCode, "validation error", "dbs.API.UpdateDataset")
+	}
+
+	// start transaction
+	tx, err := DB.Begin()
+	if err != nil {
+		return Error(err, TransactionErrorCode, "", "dbs.API.UpdateDataset")
+	}
+	defer tx.Rollback()
+
+	// dataset is identified by its did, provided id should match it
+	datasetId, err := GetID(tx, "datasets", "dataset_id", "did", rec.DID)
+	if err != nil {
+		msg := fmt.Sprintf("dataset %s is not found", rec.DID)
+		return Error(err, NoDataErrorCode, msg, "dbs.API.UpdateDataset")
+	}
+	if rec.DATASET_ID != 0 && rec.DATASET_ID != datasetId {
+		msg := fmt.Sprintf("dataset id %d does not match dataset %s", rec.DATASET_ID, rec.DID)
+		return Error(InvalidParamErr, ValidateErrorCode, msg, "dbs.API.UpdateDataset")
+	}
+	rec.DATASET_ID = datasetId
+
+	// keep previous provenance of the dataset in history
+	err = snapshotDataset(tx, rec.DATASET_ID, rec.DID, a.CreateBy)
+	if err != nil {
+		return Error(err, UpdateErrorCode, "", "dbs.API.UpdateDataset")
+	}
+	err = rec.Update(tx)
+	if err != nil {
+		return Error(err, UpdateErrorCode, "", "dbs.API.UpdateDataset")
+	}
+	err = tx.Commit()
+	if err != nil {
+		return Error(err, CommitErrorCode, "", "dbs.API.UpdateDataset")
+	}
+	return nil
+}
+
+// DatasetDeleteReport represents summary of records removed along with a dataset
+type DatasetDeleteReport struct {
+	Did          string   `json:"did"`
+	DatasetId    int64    `json:"dataset_id"`
+	Files        int64    `json:"datasets_files"`
+	Environments int64    `json:"datasets_environments"`
+	Scripts      int64    `json:"datasets_scripts"`
+	Configs      int64    `json:"datasets_configs"`
+	Buckets      int64    `json:"buckets"`
+	Parents      int64    `json:"parents"`
+	Children     []string `json:"children"`
 }
+
+// DeleteDataset deletes dataset and all its relationships from DB.
+// If dataset is a parent of other datasets the API requires cascade=true
+// parameter which will remove parent links of children datasets as well.
 func (a *API) DeleteDataset() error {
-	// extract payload from API and initialize dataset attributes
-	data, err := io.ReadAll(a.Reader)
+	did, err := getSingleValue(a.Params, "dataset")
+	if err != nil || did == "" {
+		msg := "no dataset did is provided"
+		return Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.API.DeleteDataset")
+	}
+	cascade, err := getBoolParam(a.Params, "cascade")
 	if err != nil {
-		msg := "unable to read from API reader"
-		return Error(err, ReaderErrorCode, msg, "dbs.API.DeleteDataset")
+		return Error(err, ParametersErrorCode, "", "dbs.API.DeleteDataset")
 	}
 
-	rec := &Datasets{}
-	return DBOperation("delete", rec, data, "dbs.DeleteDatset")
+	// start transaction
+	tx, err := DB.Begin()
+	if err != nil {
+		return Error(err, TransactionErrorCode, "", "dbs.API.DeleteDataset")
+	}
+	defer tx.Rollback()
+
+	rec := &Datasets{DID: did}
+	// keep last provenance of removed dataset in history
+	if datasetId, err := GetID(tx, "datasets", "dataset_id", "did", did); err == nil {
+		err = snapshotDataset(tx, datasetId, did, a.CreateBy)
+		if err != nil {
+			return Error(err, DeleteErrorCode, "", "dbs.API.DeleteDataset")
+		}
+	}
+	report, err := rec.remove(tx, cascade)
+	if err != nil {
+		msg := fmt.Sprintf("unable to delete dataset %s", did)
+		return Error(err, DeleteErrorCode, msg, "dbs.API.DeleteDataset")
+	}
+	err = tx.Commit()
+	if err != nil {
+		return Error(err, CommitErrorCode, "", "dbs.API.DeleteDataset")
+	}
+
+	// write delete report back to client
+	data, err := json.Marshal([]DatasetDeleteReport{report})
+	if err != nil {
+		return Error(err, MarshalErrorCode, "", "dbs.API.DeleteDataset")
+	}
+	a.Writer.Write(data)
+	return nil
 }
 
 // Delete implementation of Datasets
 func (r *Datasets) Delete(tx *sql.Tx) error {
-	return nil
+	_, err := r.remove(tx, false)
+	return err
+}
+
+// helper function to remove dataset record along with all its relationships
+//
+//gocyclo:ignore
+func (r *Datasets) remove(tx *sql.Tx, cascade bool) (DatasetDeleteReport, error) {
+	var err error
+	report := DatasetDeleteReport{Did: r.DID, Children: []string{}}
+	if r.DATASET_ID == 0 {
+		r.DATASET_ID, err = GetID(tx, "datasets", "dataset_id", "did", r.DID)
+		if err != nil {
+			msg := fmt.Sprintf("dataset %s is not found", r.DID)
+			return report, Error(err, GetIDErrorCode, msg, "dbs.datasets.remove")
+		}
+	}
+	report.DatasetId = r.DATASET_ID
+
+	// check if other datasets still use our dataset as their parent
+	children, err := childDids(tx, r.DATASET_ID)
+	if err != nil {
+		return report, Error(err, QueryErrorCode, "unable to look-up children", "dbs.datasets.remove")
+	}
+	if len(children) > 0 && !cascade {
+		msg := fmt.Sprintf(
+			"dataset %s is parent of %v, please use cascade=true to remove it",
+			r.DID, children)
+		return report, Error(InvalidRequestErr, ParentsErrorCode, msg, "dbs.datasets.remove")
+	}
+	report.Children = children
+
+	// remove all dataset relationships
+	if report.Files, err = DeleteManyToMany(tx, "delete_dataset_file", r.DATASET_ID); err != nil {
+		return report, err
+	}
+	if report.Environments, err = DeleteManyToMany(tx, "delete_dataset_environment", r.DATASET_ID); err != nil {
+		return report, err
+	}
+	if report.Scripts, err = DeleteManyToMany(tx, "delete_dataset_script", r.DATASET_ID); err != nil {
+		return report, err
+	}
+	if report.Configs, err = DeleteManyToMany(tx, "delete_dataset_config", r.DATASET_ID); err != nil {
+		return report, err
+	}
+	if report.Buckets, err = DeleteManyToMany(tx, "delete_dataset_bucket", r.DATASET_ID); err != nil {
+		return report, err
+	}
+	nparents, err := DeleteManyToMany(tx, "delete_dataset_parent", r.DATASET_ID)
+	if err != nil {
+		return report, err
+	}
+	nchildren, err := DeleteManyToMany(tx, "delete_dataset_child", r.DATASET_ID)
+	if err != nil {
+		return report, err
+	}
+	report.Parents = nparents + nchildren
+
+	// finally remove dataset itself
+	stm := getSQL("delete_dataset")
+	if Verbose > 0 {
+		log.Printf("Delete Datasets\n%s\n%+v", stm, r)
+	}
+	if _, err = tx.Exec(stm, r.DATASET_ID); err != nil {
+		return report, Error(err, DeleteErrorCode, "", "dbs.datasets.remove")
+	}
+	return report, nil
+}
+
+// helper function to find dids of all datasets which have given dataset id as a parent
+func childDids(tx *sql.Tx, datasetId int64) ([]string, error) {
+	var out []string
+	tmpl := make(map[string]any)
+	tmpl["Owner"] = DBOWNER
+	stm, err := LoadTemplateSQL("select_child", tmpl)
+	if err != nil {
+		return out, err
+	}
+	cond := fmt.Sprintf("dsp.parent_id = %s", placeholder("parent_id"))
+	stm = WhereClause(stm, []string{cond})
+	rows, err := tx.Query(stm, datasetId)
+	if err != nil {
+		return out, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var child, did sql.NullString
+		var cat, cby, mat, mby any
+		if err := rows.Scan(&child, &did, &cat, &cby, &mat, &mby); err != nil {
+			return out, err
+		}
+		if child.Valid {
+			out = append(out, child.String)
+		}
+	}
+	return out, rows.Err()
 }
 
 // Update implementation of Datasets
@@ -446,11 +719,12 @@ func (r *Datasets) Update(tx *sql.Tx) error {
 	if Verbose > 0 {
 		log.Printf("Update Datasets\n%s\n%+v", stm, r)
 	}
-	// make final SQL statement to insert dataset record
+	// ids which are not provided keep their stored values
 	_, err = tx.Exec(
 		stm,
-		r.SITE_ID,
-		r.PROCESSING_ID,
+		nullID(r.SITE_ID),
+		nullID(r.PROCESSING_ID),
+		nullID(r.OSINFO_ID),
 		r.MODIFY_AT,
 		r.MODIFY_BY,
 		r.DATASET_ID,
@@ -466,15 +740,13 @@ func (r *Datasets) Update(tx *sql.Tx) error {
 
 // Insert implementation of Datasets
 func (r *Datasets) Insert(tx *sql.Tx) (int64, error) {
-	var tid int64
 	var err error
-	if r.DATASET_ID == 0 {
-		if DBOWNER == "sqlite" || DBOWNER == "mysql" {
-			tid, err = LastInsertID(tx, "datasets", "dataset_id")
-			r.DATASET_ID = tid + 1
+	// SQLite and MySQL assign dataset id on insert
+	if r.DATASET_ID == 0 && !autoIncrement() {
+		if DBOWNER == "postgres" {
+			r.DATASET_ID, err = SequenceNextID(tx, "datasets", "dataset_id")
 		} else {
-			tid, err = IncrementSequence(tx, "SEQ_DS")
-			r.DATASET_ID = tid
+			r.DATASET_ID, err = IncrementSequence(tx, "SEQ_DS")
 		}
 	}
 	if err != nil {
@@ -493,14 +765,15 @@ func (r *Datasets) Insert(tx *sql.Tx) (int64, error) {
 		log.Printf("Insert Datasets\n%s\n%+v", stm, r)
 	}
 	// make final SQL statement to insert dataset record
-	_, err = tx.Exec(
+	r.DATASET_ID, err = execInsert(
+		tx,
 		stm,
 		r.DATASET_ID,
 		r.DID,
-		r.SITE_ID,
-		r.PROCESSING_ID,
-		r.OSINFO_ID,
-		r.CONFIG_ID,
+		nullID(r.SITE_ID),
+		nullID(r.PROCESSING_ID),
+		nullID(r.OSINFO_ID),
+		nullID(r.CONFIG_ID),
 		r.CREATE_AT,
 		r.CREATE_BY,
 		r.MODIFY_AT,
@@ -0,0 +1,103 @@
+package dbs
+
+// DBS dataset status module
+//
+// nolint: gocyclo
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/CHESSComputing/golib/utils"
+)
+
+// dataset statuses
+const (
+	DatasetValid      = "VALID"
+	DatasetInvalid    = "INVALID"
+	DatasetDeprecated = "DEPRECATED"
+	DatasetProduction = "PRODUCTION"
+)
+
+// DatasetStatuses lists supported dataset statuses
+var DatasetStatuses = []string{DatasetValid, DatasetInvalid, DatasetDeprecated, DatasetProduction}
+
+// DatasetStatusTransitions defines permitted transitions of dataset status:
+// new datasets are VALID and can be promoted to PRODUCTION, deprecated or
+// invalidated (retracted). Deprecated and invalid datasets can be restored
+// back to VALID state.
+var DatasetStatusTransitions = map[string][]string{
+	DatasetValid:      {DatasetProduction, DatasetDeprecated, DatasetInvalid},
+	DatasetProduction: {DatasetDeprecated, DatasetInvalid},
+	DatasetDeprecated: {DatasetValid, DatasetInvalid},
+	DatasetInvalid:    {DatasetValid},
+}
+
+// DatasetStatusRecord represents request to change status of a dataset
+type DatasetStatusRecord struct {
+	Did      string `json:"did"`
+	Status   string `json:"status"`
+	Previous string `json:"previous,omitempty"`
+}
+
+// UpdateDatasetStatus changes status of a dataset
+func (a *API) UpdateDatasetStatus() error {
+	data, err := io.ReadAll(a.Reader)
+	if err != nil {
+		return Error(err, ReaderErrorCode, "", "dbs.datasetstatus.UpdateDatasetStatus")
+	}
+	var rec DatasetStatusRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return Error(err, UnmarshalErrorCode, "", "dbs.datasetstatus.UpdateDatasetStatus")
+	}
+	rec.Status = strings.ToUpper(rec.Status)
+	if rec.Did == "" {
+		msg := "dataset status record requires did"
+		return Error(InvalidParamErr, ValidateErrorCode, msg, "dbs.datasetstatus.UpdateDatasetStatus")
+	}
+	if !utils.InList(rec.Status, DatasetStatuses) {
+		msg := fmt.Sprintf("invalid status '%s', supported statuses: %v", rec.Status, DatasetStatuses)
+		return Error(InvalidParamErr, ValidateErrorCode, msg, "dbs.datasetstatus.UpdateDatasetStatus")
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return Error(err, TransactionErrorCode, "", "dbs.datasetstatus.UpdateDatasetStatus")
+	}
+	defer tx.Rollback()
+	var datasetId int64
+	err = tx.QueryRow(getSQL("select_dataset_status"), rec.Did).Scan(&datasetId, &rec.Previous)
+	if err == sql.ErrNoRows {
+		msg := fmt.Sprintf("dataset %s is not found", rec.Did)
+		return Error(RecordErr, NoDataErrorCode, msg, "dbs.datasetstatus.UpdateDatasetStatus")
+	} else if err != nil {
+		return Error(err, QueryErrorCode, "", "dbs.datasetstatus.UpdateDatasetStatus")
+	}
+	if rec.Previous != rec.Status {
+		if !utils.InList(rec.Status, DatasetStatusTransitions[rec.Previous]) {
+			msg := fmt.Sprintf("transition of dataset %s from %s to %s is not permitted, allowed statuses: %v",
+				rec.Did, rec.Previous, rec.Status, DatasetStatusTransitions[rec.Previous])
+			return Error(InvalidRequestErr, InvalidRequestErrorCode, msg, "dbs.datasetstatus.UpdateDatasetStatus")
+		}
+		// keep previous provenance and status of the dataset in history
+		if err := snapshotDataset(tx, datasetId, rec.Did, a.CreateBy); err != nil {
+			return Error(err, UpdateErrorCode, "", "dbs.datasetstatus.UpdateDatasetStatus")
+		}
+		_, err = tx.Exec(getSQL("update_dataset_status"), rec.Status, Date(), a.CreateBy, datasetId)
+		if err != nil {
+			return Error(err, UpdateErrorCode, "", "dbs.datasetstatus.UpdateDatasetStatus")
+		}
+		if err := tx.Commit(); err != nil {
+			return Error(err, CommitErrorCode, "", "dbs.datasetstatus.UpdateDatasetStatus")
+		}
+	}
+	data, err = json.Marshal([]DatasetStatusRecord{rec})
+	if err != nil {
+		return Error(err, MarshalErrorCode, "", "dbs.datasetstatus.UpdateDatasetStatus")
+	}
+	a.Writer.Write(data)
+	return nil
+}
@@ -15,6 +15,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -26,7 +27,8 @@ import (
 // Verbose controls verbosity level
 var Verbose int
 
-// StaticDir provides location of static directory
+// StaticDir provides location of optional on-disk static directory which
+// overrides static area embedded into DBS server, see StaticFS
 var StaticDir string
 
 // API structure represents DBS API. Each API has reader (to read
@@ -233,7 +235,7 @@ func insertRecord(rec DBRecord, r io.Reader) error {
 
 // LoadTemplateSQL function loads DBS SQL templated statements
 func LoadTemplateSQL(tmpl string, tmplData map[string]any) (string, error) {
-	sdir := fmt.Sprintf("%s/sql", StaticDir)
+	sdir := "sql"
 	if !strings.HasSuffix(tmpl, ".sql") {
 		tmpl += ".sql"
 	}
@@ -247,7 +249,7 @@ func LoadTemplateSQL(tmpl string, tmplData map[string]any) (string, error) {
 	if owner, ok := tmplData["Owner"]; ok && owner == "sqlite" {
 		stm = strings.Replace(stm, "sqlite.", "", -1)
 	}
-	if DBOWNER == "sqlite" || DBOWNER == "mysql" {
+	if commonDialect() {
 		stm = ReplaceBinds(stm)
 	}
 	return stm, nil
@@ -257,12 +259,16 @@ func LoadTemplateSQL(tmpl string, tmplData map[string]any) (string, error) {
 func LoadSQL(owner string) map[string]any {
 	tmplData := make(map[string]any)
 	tmplData["Owner"] = owner
-	sdir := fmt.Sprintf("%s/sql", StaticDir)
+	sdir := "sql"
 	if Verbose > 1 {
-		log.Println("sql area", sdir)
+		log.Println("sql area", sdir, "static dir", StaticDir)
+	}
+	files, err := ListStaticFiles(sdir)
+	if err != nil {
+		log.Fatal("unable to list SQL files", err)
 	}
 	dbsql := make(map[string]any)
-	for _, f := range ListFiles(sdir) {
+	for _, f := range files {
 		k := strings.Split(f, ".")[0]
 		stm, err := ParseTmpl(sdir, f, tmplData)
 		if err != nil {
@@ -322,7 +328,7 @@ func getSQL(key string) string {
 		log.Fatal(msg)
 	}
 	stm := val.(string)
-	if DBOWNER == "sqlite" || DBOWNER == "mysql" {
+	if commonDialect() {
 		stm = ReplaceBinds(stm)
 	}
 	return stm
@@ -360,6 +366,78 @@ func getSingleValue(params map[string]any, key string) (string, error) {
 	return "", Error(InvalidParamErr, ParseErrorCode, msg, "dbs.getSingleValue")
 }
 
+// helper function to get boolean value of optional parameter
+func getBoolParam(params map[string]any, key string) (bool, error) {
+	if _, ok := params[key]; !ok {
+		return false, nil
+	}
+	val, err := getSingleValue(params, key)
+	if err != nil {
+		return false, err
+	}
+	flag, err := strconv.ParseBool(val)
+	if err != nil {
+		msg := fmt.Sprintf("invalid %s value '%s'", key, val)
+		return false, Error(err, ParametersErrorCode, msg, "dbs.getBoolParam")
+	}
+	return flag, nil
+}
+
+// TimeFilters defines parameters of time range and creator filters which are
+// supported by dataset and file APIs
+var TimeFilters = []string{"created_after", "created_before", "modified_since", "create_by"}
+
+// helper function to parse timestamp given either as unix time or RFC3339
+// date, e.g. 1700000000 or 2024-11-14T22:13:20Z
+func parseTimestamp(val string) (int64, error) {
+	val = strings.Trim(val, " ")
+	if tstamp, err := strconv.ParseInt(val, 10, 64); err == nil {
+		return tstamp, nil
+	}
+	t, err := time.Parse(time.RFC3339, val)
+	if err != nil {
+		return 0, err
+	}
+	return t.Unix(), nil
+}
+
+// AddTimeFilters adds conditions of time range and creator filters for given
+// table alias, i.e. created_after and created_before restrict create_at,
+// modified_since restricts modify_at and create_by matches creator of records
+func AddTimeFilters(
+	alias string,
+	params map[string]any,
+	conds []string,
+	args []interface{}) ([]string, []interface{}, error) {
+
+	for _, key := range []string{"created_after", "created_before", "modified_since"} {
+		if _, ok := params[key]; !ok {
+			continue
+		}
+		val, err := getSingleValue(params, key)
+		if err != nil {
+			return conds, args, err
+		}
+		tstamp, err := parseTimestamp(val)
+		if err != nil {
+			msg := fmt.Sprintf("invalid %s '%s', should be unix time or RFC3339 date", key, val)
+			return conds, args, Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.AddTimeFilters")
+		}
+		column, op := "create_at", ">"
+		if key == "created_before" {
+			op = "<"
+		} else if key == "modified_since" {
+			column, op = "modify_at", ">="
+		}
+		conds = append(conds, fmt.Sprintf(" %s.%s %s %s", alias, column, op, placeholder(key)))
+		args = append(args, tstamp)
+	}
+	if val, ok := params["create_by"]; ok && val != "" {
+		conds, args = AddParam("create_by", alias+".create_by", params, conds, args)
+	}
+	return conds, args, nil
+}
+
 // WhereClause function construct proper SQL statement from given statement and list of conditions
 func WhereClause(stm string, conds []string) string {
 	if len(conds) == 0 {
@@ -373,14 +451,62 @@ func WhereClause(stm string, conds []string) string {
 	return strings.Trim(stm, " ")
 }
 
+// helper function to check if DB back-end uses common SQL dialect of DBS
+// statements, i.e. plain table names and positional binds. SQLite, MySQL and
+// PostgreSQL share it while ORACLE uses owner prefixed tables and named binds.
+func commonDialect() bool {
+	return DBOWNER == "sqlite" || DBOWNER == "mysql" || DBOWNER == "postgres"
+}
+
+// SQLiteOptions defines options of SQLite connections: foreign keys are
+// turned on (PRAGMA foreign_keys=ON) to enforce constraints and ON DELETE
+// CASCADE clauses of DBS schema, and transactions take write lock when they
+// begin (BEGIN IMMEDIATE) and wait for concurrent writers instead of failing
+// with "database is locked" error
+var SQLiteOptions = map[string]string{
+	"_foreign_keys": "1",
+	"_txlock":       "immediate",
+	"_busy_timeout": "10000",
+}
+
+// DataSourceName provides data source name for given DB type and URI, SQLite
+// URI is extended with SQLiteOptions which are not provided explicitly
+func DataSourceName(dbtype, dburi string) string {
+	if dbtype != "sqlite3" {
+		return dburi
+	}
+	var keys []string
+	for key := range SQLiteOptions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if strings.Contains(dburi, key+"=") {
+			continue
+		}
+		sep := "?"
+		if strings.Contains(dburi, "?") {
+			sep = "&"
+		}
+		dburi = fmt.Sprintf("%s%s%s=%s", dburi, sep, key, SQLiteOptions[key])
+	}
+	return dburi
+}
+
+// helper function to check if DB back-end assigns primary keys on insert,
+// SQLite and MySQL tables use AUTOINCREMENT ids while PostgreSQL and ORACLE
+// ids are obtained from sequences before insert
+func autoIncrement() bool {
+	return DBOWNER == "sqlite" || DBOWNER == "mysql"
+}
+
+// helper function to get placeholder of bind parameter, PostgreSQL uses
+// positional ? placeholders as well since PostgresDriver rebinds them to $n
 func placeholder(pholder string) string {
 	if DBTYPE == "ora" || DBTYPE == "oci8" {
 		return fmt.Sprintf(":%s", pholder)
-	} else if DBTYPE == "PostgreSQL" {
-		return fmt.Sprintf("$%s", pholder)
-	} else {
-		return "?"
 	}
+	return "?"
 }
 
 // helper function to generate error record
@@ -406,6 +532,10 @@ func CleanStatement(stm string) string {
 	return stm
 }
 
+// JSONColumns defines output columns which hold JSON documents, e.g.
+// content of configs
+var JSONColumns = []string{"content"}
+
 // generic API to execute given statement
 // ideas are taken from
 // http://stackoverflow.com/questions/17845619/how-to-call-the-scan-variadic-function-in-golang-using-reflection
@@ -493,8 +623,8 @@ func executeAll(w io.Writer, sep, stm string, args ...interface{}) error {
 				if e == nil {
 					rec[cols[i]] = v
 				}
-			case []uint8: // somehow in MySQL all strings are represented as uint8
-				if DBOWNER == "mysql" {
+			case []uint8: // somehow in MySQL and PostgreSQL strings may be represented as uint8
+				if DBOWNER == "mysql" || DBOWNER == "postgres" {
 					rec[cols[i]] = string(val)
 				} else {
 					rec[cols[i]] = val
@@ -503,6 +633,12 @@ func executeAll(w io.Writer, sep, stm string, args ...interface{}) error {
 				rec[cols[i]] = val
 			}
 		}
+		// JSON documents are written as JSON values rather than strings
+		for _, col := range JSONColumns {
+			if val, ok := rec[col].(string); ok && json.Valid([]byte(val)) {
+				rec[col] = json.RawMessage(val)
+			}
+		}
 		if w != nil {
 			if rowCount == 0 {
 				if sep != "" {
@@ -531,7 +667,7 @@ func executeAll(w io.Writer, sep, stm string, args ...interface{}) error {
 // QueryRow function fetches results from given table
 func QueryRow(table, id, attr string, val interface{}) (int64, error) {
 	var stm string
-	if DBOWNER == "sqlite" || DBOWNER == "mysql" {
+	if commonDialect() {
 		stm = fmt.Sprintf("SELECT %s FROM %s WHERE %s = ?", id, table, attr)
 	} else {
 		stm = fmt.Sprintf("SELECT T.%s FROM %s.%s T WHERE T.%s = :%s", id, DBOWNER, table, attr, attr)
@@ -554,7 +690,7 @@ func QueryRow(table, id, attr string, val interface{}) (int64, error) {
 // GetID function fetches table primary id for a given value
 func GetID(tx *sql.Tx, table, id, attr string, val ...interface{}) (int64, error) {
 	var stm string
-	if DBOWNER == "sqlite" || DBOWNER == "mysql" {
+	if commonDialect() {
 		stm = fmt.Sprintf("SELECT %s FROM %s WHERE %s = ?", id, table, attr)
 	} else {
 		stm = fmt.Sprintf("SELECT T.%s FROM %s.%s T WHERE T.%s = :%s", id, DBOWNER, table, attr, attr)
@@ -574,40 +710,137 @@ func GetID(tx *sql.Tx, table, id, attr string, val ...interface{}) (int64, error
 	return int64(tid), nil
 }
 
+// Inserter represents record which can be inserted into DB
+type Inserter interface {
+	Insert(tx *sql.Tx) (int64, error)
+}
+
 // GetRecID function fetches table primary id for a given value and insert it if necessary
-func GetRecID(tx *sql.Tx, rec DBRecord, table, id, attr string, val ...interface{}) (int64, error) {
-	rid, err := GetID(tx, table, id, attr, val...)
+func GetRecID(tx *sql.Tx, rec Inserter, table, id, attr string, val ...interface{}) (int64, error) {
+	return GetRecIDMulti(tx, rec, table, id, []string{attr}, val...)
+}
+
+// GetRecIDMulti function fetches table primary id for given values of
+// attributes which represent unique key of the table and inserts the record
+// if necessary. Concurrent transactions may insert the same record between
+// our look-up and insert, in that case our insert violates unique constraint
+// and we read id of the record inserted by other transaction. Therefore all
+// concurrent transactions obtain the same id of the record.
+func GetRecIDMulti(tx *sql.Tx, rec Inserter, table, id string, attrs []string, vals ...interface{}) (int64, error) {
+	rid, err := getIDMulti(tx, table, id, attrs, false, vals...)
+	if err == nil {
+		return rid, nil
+	}
+	if Verbose > 1 {
+		log.Printf("unable to find %s for %v", id, vals)
+	}
+
+	// insert record within savepoint since failed statement aborts whole
+	// PostgreSQL transaction, savepoint name should be unique among nested
+	// look-ups of different tables, e.g. environment and its osinfo
+	err = withSavepoint(tx, "insert_"+table, func() error {
+		var err error
+		rid, err = rec.Insert(tx)
+		return err
+	})
+	if err == nil {
+		return rid, nil
+	}
+	if !isUniqueViolation(err) {
+		return 0, Error(err, InsertErrorCode, "", "dbs.GetRecIDMulti")
+	}
+	if Verbose > 0 {
+		log.Printf("record of %s with %v was inserted concurrently", table, vals)
+	}
+	rid, err = getIDMulti(tx, table, id, attrs, true, vals...)
 	if err != nil {
-		if Verbose > 1 {
-			log.Printf("unable to find %s for %v", id, val)
+		return 0, Error(err, InsertErrorCode, "", "dbs.GetRecIDMulti")
+	}
+	return rid, nil
+}
+
+// helper function to execute given function within transaction savepoint,
+// changes of failed function are rolled back to the savepoint and its error
+// is returned. ORACLE does not support release of savepoints, therefore they
+// are released on other DB back-ends only.
+func withSavepoint(tx *sql.Tx, savepoint string, fn func() error) error {
+	release := DBTYPE != "oci8" && DBTYPE != "ora"
+	if _, err := tx.Exec("SAVEPOINT " + savepoint); err != nil {
+		return Error(err, TransactionErrorCode, "", "dbs.withSavepoint")
+	}
+	if err := fn(); err != nil {
+		if _, e := tx.Exec("ROLLBACK TO SAVEPOINT " + savepoint); e != nil {
+			return Error(e, TransactionErrorCode, "", "dbs.withSavepoint")
 		}
-		_, err = rec.Insert(tx)
-		if err != nil {
-			// if we have concurrent threads to insert data we may end-up with
-			// ORA-00001 error which violates unique constrain
-			if strings.Contains(err.Error(), "ORA-00001") {
-				time.Sleep(1 * time.Second)
-				_, err = rec.Insert(tx)
-				if err != nil {
-					return 0, Error(err, InsertErrorCode, "", "dbs.GetRecID")
-				}
-			} else {
-				return 0, Error(err, InsertErrorCode, "", "dbs.GetRecID")
+		if release {
+			if _, e := tx.Exec("RELEASE SAVEPOINT " + savepoint); e != nil {
+				return Error(e, TransactionErrorCode, "", "dbs.withSavepoint")
 			}
 		}
-		rid, err = GetID(tx, table, id, attr, val...)
-		if err != nil {
-			return 0, Error(err, InsertErrorCode, "", "dbs.GetRecID")
+		return err
+	}
+	if release {
+		if _, err := tx.Exec("RELEASE SAVEPOINT " + savepoint); err != nil {
+			return Error(err, TransactionErrorCode, "", "dbs.withSavepoint")
 		}
 	}
-	return rid, nil
+	return nil
+}
+
+// helper function to get primary id of record with given values of
+// attributes. MySQL transactions read snapshot of data taken at their first
+// read, therefore records committed by concurrent transactions should be
+// fetched by locking read.
+func getIDMulti(tx *sql.Tx, table, id string, attrs []string, latest bool, vals ...interface{}) (int64, error) {
+	var stm string
+	var wheres []string
+	if commonDialect() {
+		stm = fmt.Sprintf("SELECT %s FROM %s", id, table)
+		for _, a := range attrs {
+			wheres = append(wheres, fmt.Sprintf("%s = ?", a))
+		}
+	} else {
+		stm = fmt.Sprintf("SELECT T.%s FROM %s.%s T", id, DBOWNER, table)
+		for _, a := range attrs {
+			wheres = append(wheres, fmt.Sprintf("T.%s = :%s", a, a))
+		}
+	}
+	stm = fmt.Sprintf("%s WHERE %s", stm, strings.Join(wheres, " AND "))
+	if latest && DBOWNER == "mysql" {
+		stm += " LOCK IN SHARE MODE"
+	}
+	if Verbose > 1 {
+		log.Printf("getIDMulti\n%s; binding values=%+v", stm, vals)
+	}
+	var tid int64
+	if err := tx.QueryRow(stm, vals...).Scan(&tid); err != nil {
+		return 0, Error(err, QueryErrorCode, "", "dbs.getIDMulti")
+	}
+	return tid, nil
+}
+
+// helper function to check if error is violation of unique constraint
+// reported by one of supported DB back-ends
+func isUniqueViolation(err error) bool {
+	msg := err.Error()
+	for _, pat := range []string{
+		"UNIQUE constraint failed",   // SQLite
+		"Error 1062",                 // MySQL duplicate entry
+		"violates unique constraint", // PostgreSQL
+		"ORA-00001",                  // ORACLE
+	} {
+		if strings.Contains(msg, pat) {
+			return true
+		}
+	}
+	return false
 }
 
 // IfExistMulti checks if given rid exists in given table for provided value conditions
 func IfExistMulti(tx *sql.Tx, table, rid string, args []string, vals ...interface{}) bool {
 	var stm string
 	var wheres []string
-	if DBOWNER == "sqlite" || DBOWNER == "mysql" {
+	if commonDialect() {
 		stm = fmt.Sprintf("SELECT %s FROM %s", rid, table)
 		for _, a := range args {
 			wheres = append(wheres, fmt.Sprintf("%s=?", a))
@@ -705,7 +938,7 @@ func ParseRuns(runs []string) ([]string, error) {
 
 // TokenGenerator creates a SQL token generator statement
 func TokenGenerator(runs []string, limit int, name string) (string, []string) {
-	if DBOWNER == "sqlite" || DBOWNER == "mysql" {
+	if commonDialect() {
 		return TokenGeneratorSQLite(runs, name)
 	}
 	return TokenGeneratorORACLE(runs, limit, name)
@@ -753,7 +986,7 @@ func TokenGeneratorSQLite(runs []string, name string) (string, []string) {
 
 // TokenCondition provides proper condition statement for TokenGenerator
 func TokenCondition() string {
-	if DBOWNER == "sqlite" || DBOWNER == "mysql" {
+	if commonDialect() {
 		return "(SELECT token FROM TOKEN_GENERATOR WHERE token <> '')"
 	}
 	return "(SELECT TOKEN FROM TOKEN_GENERATOR)"
@@ -830,44 +1063,142 @@ func runsClause(table string, runs []string) (string, string, []string) {
 	return token, where, args
 }
 
-// AddParam adds single parameter to SQL statement
+// TokenThreshold defines number of values of query parameter above which
+// SQLite and ORACLE back-ends get them via TokenGenerator statement rather
+// than IN list of binds
+var TokenThreshold = 100
+
+// AddParam adds condition of given parameter to SQL statement. Repeated
+// parameters, e.g. site=A&site=B, are combined into IN list while values with
+// wildcards are matched via LIKE, and values with ! prefix are negated, e.g.
+// site=!A selects records with site other than A.
 func AddParam(
 	name, sqlName string,
 	params map[string]any,
 	conds []string,
 	args []interface{}) ([]string, []interface{}) {
 
-	vals := getValues(params, name)
-	if len(vals) == 1 {
-		op, val := OperatorValue(vals[0])
-		if strings.Contains(val, "e+") || strings.Contains(val, "E+") {
-			val = ConvertFloat(val)
-		}
-		if strings.Contains(val, "[") {
-			val = strings.Replace(val, "[", "", -1)
-			val = strings.Replace(val, "]", "", -1)
-			val = strings.Trim(val, " ")
+	var values, negated []string
+	for _, val := range getValues(params, name) {
+		if strings.HasPrefix(val, "!") {
+			negated = append(negated, paramValue(strings.TrimPrefix(val, "!")))
+		} else {
+			values = append(values, paramValue(val))
 		}
-		cond := fmt.Sprintf(" %s %s %s", sqlName, op, placeholder(name))
+	}
+	if len(values) > 0 {
+		cond, vals := valuesCondition(name, sqlName, values, false)
 		conds = append(conds, cond)
-		args = append(args, val)
+		args = append(args, vals...)
+	}
+	if len(negated) > 0 {
+		// negated values use their own bind names, see placeholder
+		cond, vals := valuesCondition(name+"_not", sqlName, negated, true)
+		conds = append(conds, cond)
+		args = append(args, vals...)
 	}
 	return conds, args
 }
 
+// helper function to clean up value of query parameter
+func paramValue(val string) string {
+	if strings.Contains(val, "e+") || strings.Contains(val, "E+") {
+		val = ConvertFloat(val)
+	}
+	if strings.Contains(val, "[") {
+		val = strings.Replace(val, "[", "", -1)
+		val = strings.Replace(val, "]", "", -1)
+		val = strings.Trim(val, " ")
+	}
+	return val
+}
+
+// helper function to build condition which matches any of given values or,
+// if negate flag is set, none of them
+func valuesCondition(name, sqlName string, values []string, negate bool) (string, []interface{}) {
+	var conds, list []string
+	var args []interface{}
+	for _, v := range values {
+		op, val := OperatorValue(v)
+		if op == "like" {
+			if negate {
+				op = "not like"
+			}
+			conds = append(conds, fmt.Sprintf("%s %s %s", sqlName, op, placeholder(name)))
+			args = append(args, val)
+		} else {
+			list = append(list, val)
+		}
+	}
+	if len(list) == 1 {
+		op := "="
+		if negate {
+			op = "<>"
+		}
+		conds = append(conds, fmt.Sprintf("%s %s %s", sqlName, op, placeholder(name)))
+		args = append(args, list[0])
+	} else if len(list) > 1 {
+		op := "IN"
+		if negate {
+			op = "NOT IN"
+		}
+		stm, vals := inList(name, list)
+		conds = append(conds, fmt.Sprintf("%s %s %s", sqlName, op, stm))
+		args = append(args, vals...)
+	}
+	if len(conds) == 1 {
+		return " " + conds[0], args
+	}
+	if negate {
+		return fmt.Sprintf(" (%s)", strings.Join(conds, " AND ")), args
+	}
+	return fmt.Sprintf(" (%s)", strings.Join(conds, " OR ")), args
+}
+
+// helper function to build IN list of given values. Long lists are passed to
+// SQLite and ORACLE as comma separated tokens of TokenGenerator statement
+// placed in sub-query, while MySQL and PostgreSQL use list of binds since
+// TokenGenerator statement relies on SQLite and ORACLE functions.
+func inList(name string, values []string) (string, []interface{}) {
+	var args []interface{}
+	tokens := len(values) > TokenThreshold && (DBOWNER == "sqlite" || !commonDialect())
+	for _, v := range values {
+		if strings.Contains(v, ",") {
+			tokens = false
+		}
+	}
+	if tokens {
+		token, binds := TokenGenerator(values, 4000, name+"_token") // 4000 is hard ORACLE limit
+		if commonDialect() {
+			token = ReplaceBinds(token)
+		}
+		for _, v := range binds {
+			args = append(args, v)
+		}
+		// TokenCondition is sub-query of token generator, e.g. (SELECT token ...)
+		return fmt.Sprintf("(%s%s", token, TokenCondition()[1:]), args
+	}
+	var binds []string
+	for idx, v := range values {
+		binds = append(binds, placeholder(fmt.Sprintf("%s_%d", name, idx)))
+		args = append(args, v)
+	}
+	return fmt.Sprintf("(%s)", strings.Join(binds, ", ")), args
+}
+
 // IncrementSequences API provide a way to get N unique IDs for given sequence name
 func IncrementSequences(tx *sql.Tx, seq string, n int) ([]int64, error) {
 	var out []int64
-	if DBOWNER == "sqlite" || DBOWNER == "mysql" {
-		ts := time.Now().UnixNano()
-		for i := 0; i < n; i++ {
-			out = append(out, ts+int64(i))
-		}
-		return out, nil
+	if autoIncrement() {
+		msg := fmt.Sprintf("%s back-end does not support sequences, ids are assigned on insert", DBOWNER)
+		return out, Error(InvalidRequestErr, LastInsertErrorCode, msg, "dbs.IncrementSequences")
 	}
 	var pid float64
 	for i := 0; i < n; i++ {
 		stm := fmt.Sprintf("select %s.%s.nextval as val from dual", DBOWNER, seq)
+		if DBOWNER == "postgres" {
+			stm = fmt.Sprintf("SELECT nextval('%s')", seq)
+		}
 		err := tx.QueryRow(stm).Scan(&pid)
 		if err != nil {
 			msg := fmt.Sprintf("fail to increment sequence, query='%s'", stm)
@@ -888,10 +1219,28 @@ func IncrementSequence(tx *sql.Tx, seq string) (int64, error) {
 	return 0, Error(err, LastInsertErrorCode, "", "dbs.IncrementSequence")
 }
 
-// LastInsertID returns last insert id of given table and idname parameter
+// SequenceNextID returns next value of PostgreSQL sequence which backs
+// identity column of given table
+func SequenceNextID(tx *sql.Tx, table, idName string) (int64, error) {
+	stm := fmt.Sprintf("SELECT nextval(pg_get_serial_sequence('%s', '%s'))", table, idName)
+	if Verbose > 1 {
+		log.Println("execute", stm)
+	}
+	var tid int64
+	err := tx.QueryRow(stm).Scan(&tid)
+	if err != nil {
+		msg := fmt.Sprintf("fail to process query='%s'", stm)
+		log.Println(msg)
+		return 0, Error(err, QueryErrorCode, "", "dbs.SequenceNextID")
+	}
+	return tid, nil
+}
+
+// LastInsertID returns last insert id of given table and idname parameter,
+// it should not be used to allocate new ids, see execInsert
 func LastInsertID(tx *sql.Tx, table, idName string) (int64, error) {
 	stm := fmt.Sprintf("select MAX(%s) from %s.%s", idName, DBOWNER, table)
-	if DBOWNER == "sqlite" || DBOWNER == "mysql" {
+	if commonDialect() {
 		stm = fmt.Sprintf("select MAX(%s) from %s", idName, table)
 	}
 	var pid sql.NullFloat64
@@ -985,13 +1334,41 @@ func getInt64(record map[string]any, attr string) int64 {
 	return 0
 }
 
-// helper function to get next available SiteID
+// helper function to execute insert statement of a record, the first bind
+// argument is id of the record. Zero id is assigned by the database via
+// AUTOINCREMENT (SQLite and MySQL) and obtained from result of the statement,
+// therefore concurrent inserts never share the same id. It returns id of
+// inserted record.
+func execInsert(tx *sql.Tx, stm string, id int64, args ...interface{}) (int64, error) {
+	var rid interface{} = id
+	if id == 0 {
+		rid = nil
+	}
+	res, err := tx.Exec(stm, append([]interface{}{rid}, args...)...)
+	if err != nil {
+		return 0, err
+	}
+	if id == 0 {
+		return res.LastInsertId()
+	}
+	return id, nil
+}
+
+// helper function to bind optional reference to another table, zero id means
+// no reference and it is stored as NULL to satisfy foreign key constraints
+func nullID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
+
+// helper function to get next available id of given table, it returns zero
+// for SQLite and MySQL back-ends since their ids are assigned on insert
 func getNextId(tx *sql.Tx, table, tableId string) (int64, error) {
 	var err error
 	var tid int64
-	if DBOWNER == "sqlite" || DBOWNER == "mysql" {
-		tid, err = LastInsertID(tx, table, tableId)
-		tid += 1
+	if autoIncrement() {
+		return 0, nil
+	} else if DBOWNER == "postgres" {
+		tid, err = SequenceNextID(tx, table, tableId)
 	} else {
 		tid, err = IncrementSequence(tx, "SEQ_FL")
 	}
@@ -43,26 +43,17 @@ func (e *EnvironmentRecord) Insert(tx *sql.Tx) (int64, error) {
 
 	// find out osinfo id from given OSName
 	if e.OSName != "" {
-		os_id, err := GetID(tx, "osinfo", "os_id", "name", e.OSName)
-		if err == nil {
-			if os_id != 0 {
-				r.OS_ID = &os_id
-			} else {
-				r.OS_ID = nil
-			}
+		// if no os_name found in environment record we will try to insert it here
+		osRec := OsInfoRecord{Name: e.OSName, Version: "N/A", Kernel: "N/A"}
+		os_id, err := GetRecID(tx, &osRec, "osinfo", "os_id", "name", e.OSName)
+		if err != nil {
+			msg := "fail to insert OsInfoRecord"
+			return 0, Error(err, OsInfoErrorCode, msg, "dbs.EnvironmentRecord.Insert")
+		}
+		if os_id != 0 {
+			r.OS_ID = &os_id
 		} else {
-			// if no os_name found in environment record we will try to insert it here
-			osRec := OsInfoRecord{Name: e.OSName, Version: "N/A", Kernel: "N/A"}
-			os_id, err := osRec.Insert(tx)
-			if err != nil {
-				msg := "fail to insert OsInfoRecord"
-				return 0, Error(err, OsInfoErrorCode, msg, "dbs.EnvironmentRecord.Insert")
-			}
-			if os_id != 0 {
-				r.OS_ID = &os_id
-			} else {
-				r.OS_ID = nil
-			}
+			r.OS_ID = nil
 		}
 	}
 
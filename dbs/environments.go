@@ -46,11 +46,15 @@ func (a *API) GetEnvironment() error {
 	if val, ok := a.Params["did"]; ok && val != "" {
 		conds, args = AddParam("did", "d.did", a.Params, conds, args)
 	}
+	conds, args, err = addFullTextParam("environments", "e", a.Params, conds, args)
+	if err != nil {
+		return Error(err, ParametersErrorCode, "", "dbs.environments.Environments")
+	}
 
 	stm = WhereClause(stm, conds)
 
 	// use generic query API to fetch the results from DB
-	err = executeAll(a.Writer, a.Separator, stm, args...)
+	err = a.executePage(stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "", "dbs.environments.Environments")
 	}
@@ -159,7 +163,8 @@ func (r *Environments) Insert(tx *sql.Tx) (int64, error) {
 	} else if Verbose > 1 {
 		log.Printf("Insert Environments\n%s\n%+v", stm, r)
 	}
-	_, err = tx.Exec(
+	r.ENVIRONMENT_ID, err = execInsert(
+		tx,
 		stm,
 		r.ENVIRONMENT_ID,
 		r.NAME,
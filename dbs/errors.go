@@ -33,6 +33,9 @@ var ContentTypeErr = errors.New("content-type error")
 // NotImplementedApiErr represents generic not implemented api error
 var NotImplementedApiErr = errors.New("not implemented api error")
 
+// MigrationErr represents generic schema migration error
+var MigrationErr = errors.New("migration error")
+
 // InvalidRequestErr represents generic invalid request error
 var InvalidRequestErr = errors.New("invalid request error")
 
@@ -7,9 +7,11 @@ package dbs
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
+	"strings"
 
 	lexicon "github.com/CHESSComputing/golib/lexicon"
 )
@@ -54,6 +56,21 @@ func (a *API) GetFile() error {
 			conds, args = AddParam("file_type", "df.file_type", a.Params, conds, args)
 		}
 	}
+	if val, ok := a.Params["is_file_valid"]; ok {
+		if val != "" {
+			for _, v := range getValues(a.Params, "is_file_valid") {
+				if v = strings.TrimPrefix(v, "!"); v != "0" && v != "1" {
+					msg := fmt.Sprintf("invalid is_file_valid value %v, should be 0 or 1", v)
+					return Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.files.Files")
+				}
+			}
+			conds, args = AddParam("is_file_valid", "f.is_file_valid", a.Params, conds, args)
+		}
+	}
+	conds, args, err = AddTimeFilters("f", a.Params, conds, args)
+	if err != nil {
+		return Error(err, ParametersErrorCode, "", "dbs.files.Files")
+	}
 
 	tmpl := make(map[string]any)
 	tmpl["Owner"] = DBOWNER
@@ -65,7 +82,7 @@ func (a *API) GetFile() error {
 	stm = WhereClause(stm, conds)
 
 	// use generic query API to fetch the results from DB
-	err = executeAll(a.Writer, a.Separator, stm, args...)
+	err = a.executePage(stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "", "dbs.files.Files")
 	}
@@ -77,6 +94,9 @@ func (a *API) InsertFile() error {
 	// and cast it to Files data structure
 	return insertRecord(&Files{}, a.Reader)
 }
+
+// UpdateFile updates checksum, size and validity of existing file record in DB.
+// Only attributes provided in HTTP payload are changed.
 func (a *API) UpdateFile() error {
 	// extract payload from API and initialize file attributes
 	data, err := io.ReadAll(a.Reader)
@@ -84,30 +104,264 @@ func (a *API) UpdateFile() error {
 		msg := "unable to read from API reader"
 		return Error(err, ReaderErrorCode, msg, "dbs.API.UpdateFile")
 	}
-	rec := &Files{}
-	return DBOperation("update", rec, data, "dbs.UpdateFile")
+	var input Files
+	err = json.Unmarshal(data, &input)
+	if err != nil {
+		return Error(err, UnmarshalErrorCode, "", "dbs.API.UpdateFile")
+	}
+
+	// start transaction
+	tx, err := DB.Begin()
+	if err != nil {
+		return Error(err, TransactionErrorCode, "", "dbs.API.UpdateFile")
+	}
+	defer tx.Rollback()
+
+	// load existing file record and overwrite it with provided attributes
+	rec, err := getFileRecord(tx, input.FILE)
+	if err != nil {
+		msg := fmt.Sprintf("unable to find file %s", input.FILE)
+		return Error(err, FilesErrorCode, msg, "dbs.API.UpdateFile")
+	}
+	fid := rec.FILE_ID
+	err = json.Unmarshal(data, &rec)
+	if err != nil {
+		return Error(err, UnmarshalErrorCode, "", "dbs.API.UpdateFile")
+	}
+	rec.FILE_ID = fid
+	rec.MODIFY_BY = a.CreateBy
+	rec.MODIFY_AT = Date()
+	err = rec.Update(tx)
+	if err != nil {
+		return Error(err, UpdateErrorCode, "", "dbs.API.UpdateFile")
+	}
+	err = tx.Commit()
+	if err != nil {
+		return Error(err, CommitErrorCode, "", "dbs.API.UpdateFile")
+	}
+	return nil
 }
-func (a *API) DeleteFile() error {
-	// extract payload from API and initialize file attributes
+
+// FileInvalidateRecord represents input record to invalidate either
+// a single file or all files of a given dataset
+type FileInvalidateRecord struct {
+	File string `json:"file"`
+	Did  string `json:"did"`
+}
+
+// FileInvalidateReport represents summary of file invalidation
+type FileInvalidateReport struct {
+	File        string `json:"file,omitempty"`
+	Did         string `json:"did,omitempty"`
+	Invalidated int64  `json:"invalidated"`
+}
+
+// InvalidateFile marks file, or all files of a dataset, as invalid
+// without deleting them from DB
+func (a *API) InvalidateFile() error {
 	data, err := io.ReadAll(a.Reader)
 	if err != nil {
-		msg := "unable to reade from API reader"
-		return Error(err, ReaderErrorCode, msg, "dbs.API.DeleteFile")
+		msg := "unable to read from API reader"
+		return Error(err, ReaderErrorCode, msg, "dbs.API.InvalidateFile")
+	}
+	var rec FileInvalidateRecord
+	err = json.Unmarshal(data, &rec)
+	if err != nil {
+		return Error(err, UnmarshalErrorCode, "", "dbs.API.InvalidateFile")
+	}
+	if (rec.File == "" && rec.Did == "") || (rec.File != "" && rec.Did != "") {
+		msg := "either file or did should be provided"
+		return Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.API.InvalidateFile")
+	}
+
+	// prepare SQL statement and its arguments
+	var stm string
+	var args []interface{}
+	args = append(args, 0, Date(), a.CreateBy)
+	if rec.File != "" {
+		if err := lexicon.CheckPattern("file", rec.File); err != nil {
+			return Error(err, ValidateErrorCode, "", "dbs.API.InvalidateFile")
+		}
+		stm = getSQL("update_file_validity")
+		args = append(args, rec.File)
+	} else {
+		if err := lexicon.CheckPattern("did", rec.Did); err != nil {
+			return Error(err, ValidateErrorCode, "", "dbs.API.InvalidateFile")
+		}
+		stm = getSQL("update_dataset_files_validity")
+		args = append(args, rec.Did)
+	}
+	if Verbose > 0 {
+		log.Printf("Invalidate Files\n%s\n%+v", stm, args)
+	}
+
+	// start transaction
+	tx, err := DB.Begin()
+	if err != nil {
+		return Error(err, TransactionErrorCode, "", "dbs.API.InvalidateFile")
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec(stm, args...)
+	if err != nil {
+		return Error(err, UpdateErrorCode, "", "dbs.API.InvalidateFile")
 	}
-	rec := &Files{}
-	return DBOperation("delete", rec, data, "dbs.DeleteFile")
+	nrows, err := res.RowsAffected()
+	if err != nil {
+		return Error(err, UpdateErrorCode, "", "dbs.API.InvalidateFile")
+	}
+	if nrows == 0 {
+		msg := fmt.Sprintf("no files found for %+v", rec)
+		return Error(InvalidParamErr, FilesErrorCode, msg, "dbs.API.InvalidateFile")
+	}
+	err = tx.Commit()
+	if err != nil {
+		return Error(err, CommitErrorCode, "", "dbs.API.InvalidateFile")
+	}
+
+	// write invalidation report back to client
+	report := FileInvalidateReport{File: rec.File, Did: rec.Did, Invalidated: nrows}
+	data, err = json.Marshal([]FileInvalidateReport{report})
+	if err != nil {
+		return Error(err, MarshalErrorCode, "", "dbs.API.InvalidateFile")
+	}
+	a.Writer.Write(data)
+	return nil
+}
+
+// FileDeleteReport represents summary of records removed along with a file
+type FileDeleteReport struct {
+	File     string `json:"file"`
+	FileId   int64  `json:"file_id"`
+	Datasets int64  `json:"datasets_files"`
+}
+
+// DeleteFile deletes file and its dataset relationships from DB
+func (a *API) DeleteFile() error {
+	name, err := getSingleValue(a.Params, "file")
+	if err != nil || name == "" {
+		msg := "no file name is provided"
+		return Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.API.DeleteFile")
+	}
+
+	// start transaction
+	tx, err := DB.Begin()
+	if err != nil {
+		return Error(err, TransactionErrorCode, "", "dbs.API.DeleteFile")
+	}
+	defer tx.Rollback()
+
+	rec := &Files{FILE: name}
+	report, err := rec.remove(tx)
+	if err != nil {
+		msg := fmt.Sprintf("unable to delete file %s", name)
+		return Error(err, DeleteErrorCode, msg, "dbs.API.DeleteFile")
+	}
+	err = tx.Commit()
+	if err != nil {
+		return Error(err, CommitErrorCode, "", "dbs.API.DeleteFile")
+	}
+
+	// write delete report back to client
+	data, err := json.Marshal([]FileDeleteReport{report})
+	if err != nil {
+		return Error(err, MarshalErrorCode, "", "dbs.API.DeleteFile")
+	}
+	a.Writer.Write(data)
+	return nil
 }
 
 // Delete implementation of Files
 func (r *Files) Delete(tx *sql.Tx) error {
-	return nil
+	_, err := r.remove(tx)
+	return err
+}
+
+// helper function to remove file record along with its dataset relationships
+func (r *Files) remove(tx *sql.Tx) (FileDeleteReport, error) {
+	var err error
+	report := FileDeleteReport{File: r.FILE}
+	if r.FILE_ID == 0 {
+		r.FILE_ID, err = GetID(tx, "files", "file_id", "file", r.FILE)
+		if err != nil {
+			msg := fmt.Sprintf("file %s is not found", r.FILE)
+			return report, Error(err, GetIDErrorCode, msg, "dbs.files.remove")
+		}
+	}
+	report.FileId = r.FILE_ID
+	report.Datasets, err = DeleteManyToMany(tx, "delete_file_dataset", r.FILE_ID)
+	if err != nil {
+		return report, err
+	}
+	stm := getSQL("delete_file")
+	if Verbose > 0 {
+		log.Printf("Delete Files\n%s\n%+v", stm, r)
+	}
+	if _, err = tx.Exec(stm, r.FILE_ID); err != nil {
+		return report, Error(err, DeleteErrorCode, "", "dbs.files.remove")
+	}
+	return report, nil
 }
 
 // Update implementation of Files
 func (r *Files) Update(tx *sql.Tx) error {
+	var err error
+	if r.FILE_ID == 0 {
+		r.FILE_ID, err = GetID(tx, "files", "file_id", "file", r.FILE)
+		if err != nil {
+			msg := fmt.Sprintf("file %s is not found", r.FILE)
+			return Error(err, GetIDErrorCode, msg, "dbs.files.Update")
+		}
+	}
+	// set defaults and validate the record
+	r.SetDefaults()
+	err = r.Validate()
+	if err != nil {
+		log.Println("unable to validate record", err)
+		return Error(err, ValidateErrorCode, "", "dbs.files.Update")
+	}
+	// get SQL statement from static area
+	stm := getSQL("update_file")
+	if Verbose > 0 {
+		log.Printf("Update Files\n%s\n%+v", stm, r)
+	}
+	_, err = tx.Exec(
+		stm,
+		r.CHECKSUM,
+		r.SIZE,
+		r.IS_FILE_VALID,
+		r.MODIFY_AT,
+		r.MODIFY_BY,
+		r.FILE_ID)
+	if err != nil {
+		if Verbose > 0 {
+			log.Println("unable to update files, error", err)
+		}
+		return Error(err, UpdateErrorCode, "", "dbs.files.Update")
+	}
 	return nil
 }
 
+// helper function to load file record for a given file name
+func getFileRecord(tx *sql.Tx, name string) (Files, error) {
+	var r Files
+	var checksum, cby, mby sql.NullString
+	var size, cat, mat sql.NullInt64
+	stm := getSQL("select_file_record")
+	err := tx.QueryRow(stm, name).Scan(
+		&r.FILE_ID, &r.FILE, &checksum, &size, &r.IS_FILE_VALID,
+		&cat, &cby, &mat, &mby)
+	if err != nil {
+		return r, Error(err, QueryErrorCode, "", "dbs.files.getFileRecord")
+	}
+	r.CHECKSUM = checksum.String
+	r.SIZE = size.Int64
+	r.CREATE_AT = cat.Int64
+	r.CREATE_BY = cby.String
+	r.MODIFY_AT = mat.Int64
+	r.MODIFY_BY = mby.String
+	return r, nil
+}
+
 // Insert implementation of Files
 func (r *Files) Insert(tx *sql.Tx) (int64, error) {
 	var err error
@@ -133,7 +387,8 @@ func (r *Files) Insert(tx *sql.Tx) (int64, error) {
 	} else if Verbose > 1 {
 		log.Printf("Insert Files\n%s\n%+v", stm, r)
 	}
-	_, err = tx.Exec(
+	r.FILE_ID, err = execInsert(
+		tx,
 		stm,
 		r.FILE_ID,
 		r.FILE,
@@ -161,6 +416,10 @@ func (r *Files) Validate() error {
 	if err := lexicon.CheckPattern("file", r.FILE); err != nil {
 		return Error(err, ValidateErrorCode, "", "dbs.files.Validate")
 	}
+	if r.IS_FILE_VALID != 0 && r.IS_FILE_VALID != 1 {
+		msg := "is_file_valid should be either 0 or 1"
+		return Error(InvalidParamErr, ValidateErrorCode, msg, "dbs.files.Validate")
+	}
 	if matched := lexicon.UnixTimePattern.MatchString(fmt.Sprintf("%d", r.CREATE_AT)); !matched {
 		msg := "invalid pattern for creation date"
 		return Error(InvalidParamErr, ValidateErrorCode, msg, "dbs.files.Validate")
@@ -0,0 +1,115 @@
+package dbs
+
+// DBS full-text search module
+//
+// Free-form text of configs (content), scripts (options) and environments
+// (details) is indexed by full-text engine of DB back-end, i.e. FTS4 tables
+// in SQLite, FULLTEXT indexes in MySQL and GIN indexes of tsvector in
+// PostgreSQL, see 0006_fulltext migration. SQLite uses FTS4 module since it
+// is compiled into go-sqlite3 driver by default while FTS5 requires
+// sqlite_fts5 build tag. Other back-ends fall back to case-insensitive
+// substring match. Configs are JSON documents, see 0007_config_json
+// migration, and their text representation is indexed.
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FullTextColumns defines id and indexed text column of tables which
+// support full-text search
+var FullTextColumns = map[string][2]string{
+	"configs":      {"config_id", "content"},
+	"scripts":      {"script_id", "options"},
+	"environments": {"environment_id", "details"},
+}
+
+// helper function to split full-text query into words, double quotes are
+// removed since every word is matched as a phrase
+func ftsWords(query string) []string {
+	return strings.Fields(strings.Replace(query, "\"", " ", -1))
+}
+
+// FullTextCondition provides SQL condition and its bind values which match
+// records of given table whose text column contains all words of query.
+// The alias is alias of the table in SQL statement.
+func FullTextCondition(table, alias, query string) (string, []interface{}, error) {
+	var args []interface{}
+	cols, ok := FullTextColumns[table]
+	if !ok {
+		msg := fmt.Sprintf("table %s does not support full-text search", table)
+		return "", args, Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.FullTextCondition")
+	}
+	words := ftsWords(query)
+	if len(words) == 0 {
+		msg := "empty full-text query"
+		return "", args, Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.FullTextCondition")
+	}
+	id, column := cols[0], cols[1]
+	var cond string
+	switch DBOWNER {
+	case "sqlite":
+		// words are quoted to match them as phrases, e.g. file paths,
+		// instead of interpreting them as FTS query syntax
+		var phrases []string
+		for _, word := range words {
+			phrases = append(phrases, fmt.Sprintf("\"%s\"", word))
+		}
+		cond = fmt.Sprintf(" %s.%s IN (SELECT docid FROM %s_fts WHERE %s_fts MATCH %s)",
+			alias, id, table, table, placeholder("q"))
+		args = append(args, strings.Join(phrases, " "))
+	case "mysql":
+		// JSON content of configs is indexed via generated text column
+		if table == "configs" {
+			column = "content_text"
+		}
+		var phrases []string
+		for _, word := range words {
+			phrases = append(phrases, fmt.Sprintf("+\"%s\"", word))
+		}
+		cond = fmt.Sprintf(" MATCH(%s.%s) AGAINST (%s IN BOOLEAN MODE)", alias, column, placeholder("q"))
+		args = append(args, strings.Join(phrases, " "))
+	case "postgres":
+		// expression should match the one of full-text index, JSONB content
+		// of configs is indexed as text
+		expr := fmt.Sprintf("%s.%s", alias, column)
+		if table == "configs" {
+			expr += "::text"
+		}
+		cond = fmt.Sprintf(" to_tsvector('simple', COALESCE(%s, '')) @@ plainto_tsquery('simple', %s)",
+			expr, placeholder("q"))
+		args = append(args, strings.Join(words, " "))
+	default:
+		var conds []string
+		for idx, word := range words {
+			conds = append(conds, fmt.Sprintf("UPPER(%s.%s) LIKE UPPER(%s)",
+				alias, column, placeholder(fmt.Sprintf("q%d", idx))))
+			args = append(args, "%"+word+"%")
+		}
+		cond = fmt.Sprintf(" %s", strings.Join(conds, " AND "))
+	}
+	return cond, args, nil
+}
+
+// helper function to add full-text condition of q parameter
+func addFullTextParam(
+	table, alias string,
+	params map[string]any,
+	conds []string,
+	args []interface{}) ([]string, []interface{}, error) {
+
+	if _, ok := params["q"]; !ok {
+		return conds, args, nil
+	}
+	query, err := getSingleValue(params, "q")
+	if err != nil {
+		return conds, args, err
+	}
+	cond, vals, err := FullTextCondition(table, alias, query)
+	if err != nil {
+		return conds, args, err
+	}
+	conds = append(conds, cond)
+	args = append(args, vals...)
+	return conds, args, nil
+}
@@ -0,0 +1,249 @@
+package dbs
+
+// DBS dataset history module
+//
+// nolint: gocyclo
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/CHESSComputing/golib/utils"
+)
+
+// DatasetHistory represents version of dataset provenance. Every change of
+// a dataset stores snapshot of its previous provenance along with time and
+// identity of the change, the current provenance is the last version.
+type DatasetHistory struct {
+	Did        string          `json:"did"`
+	Version    int64           `json:"version"`
+	ReplacedAt int64           `json:"replaced_at,omitempty"` // time when version was replaced
+	ReplacedBy string          `json:"replaced_by,omitempty"` // identity of the change
+	Current    bool            `json:"current"`
+	Provenance json.RawMessage `json:"provenance"`
+}
+
+// GetDatasetHistory provides history of dataset provenance. The did
+// parameter is required, the version parameter selects given version and
+// as_of parameter (unix time) selects version which was valid at that time.
+func (a *API) GetDatasetHistory() error {
+	for k := range a.Params {
+		if !utils.InList(k, []string{"did", "version", "as_of", "count"}) {
+			msg := fmt.Sprintf("invalid parameter %s", k)
+			return Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.history.GetDatasetHistory")
+		}
+	}
+	did, err := getSingleValue(a.Params, "did")
+	if err != nil || did == "" {
+		msg := "/datasets/history API requires single did"
+		return Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.history.GetDatasetHistory")
+	}
+	var version, asOf int64
+	for _, key := range []string{"version", "as_of"} {
+		if _, ok := a.Params[key]; !ok {
+			continue
+		}
+		val, _ := getSingleValue(a.Params, key)
+		num, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || num < 1 {
+			msg := fmt.Sprintf("invalid %s '%s', should be positive integer", key, val)
+			return Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.history.GetDatasetHistory")
+		}
+		if key == "version" {
+			version = num
+		} else {
+			asOf = num
+		}
+	}
+	if version > 0 && asOf > 0 {
+		msg := "version and as_of parameters are mutually exclusive"
+		return Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.history.GetDatasetHistory")
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return Error(err, TransactionErrorCode, "", "dbs.history.GetDatasetHistory")
+	}
+	defer tx.Rollback()
+	records, err := datasetHistory(tx, did)
+	if err != nil {
+		return Error(err, QueryErrorCode, "", "dbs.history.GetDatasetHistory")
+	}
+
+	// select requested version
+	if version > 0 || asOf > 0 {
+		var out []DatasetHistory
+		for _, rec := range records {
+			if (version > 0 && rec.Version == version) ||
+				(asOf > 0 && (rec.Current || rec.ReplacedAt > asOf)) {
+				out = append(out, rec)
+				break
+			}
+		}
+		records = out
+	}
+	if count, err := getBoolParam(a.Params, "count"); err != nil {
+		return Error(err, ParametersErrorCode, "", "dbs.history.GetDatasetHistory")
+	} else if count {
+		a.writeTotal(int64(len(records)))
+		return nil
+	}
+	if len(records) == 0 {
+		msg := fmt.Sprintf("no history records found for dataset %s", did)
+		return Error(RecordErr, NoDataErrorCode, msg, "dbs.history.GetDatasetHistory")
+	}
+
+	if a.Separator == "" {
+		for _, rec := range records {
+			data, err := json.Marshal(rec)
+			if err != nil {
+				return Error(err, MarshalErrorCode, "", "dbs.history.GetDatasetHistory")
+			}
+			a.Writer.Write(append(data, '\n'))
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return Error(err, MarshalErrorCode, "", "dbs.history.GetDatasetHistory")
+	}
+	a.Writer.Write(data)
+	return nil
+}
+
+// helper function to get all versions of dataset provenance, the stored
+// versions are followed by current provenance of existing dataset
+func datasetHistory(tx *sql.Tx, did string) ([]DatasetHistory, error) {
+	var records []DatasetHistory
+	rows, err := tx.Query(getSQL("select_dataset_history"), did)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var last int64
+	for rows.Next() {
+		rec := DatasetHistory{Did: did}
+		var provenance string
+		var replacedBy sql.NullString
+		if err := rows.Scan(&rec.Version, &provenance, &rec.ReplacedAt, &replacedBy); err != nil {
+			return nil, err
+		}
+		rec.ReplacedBy = replacedBy.String
+		rec.Provenance = json.RawMessage(provenance)
+		records = append(records, rec)
+		last = rec.Version
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	datasetId, err := GetID(tx, "datasets", "dataset_id", "did", did)
+	if err != nil || datasetId == 0 {
+		// dataset was removed, only its history is available
+		return records, nil
+	}
+	current, err := datasetSnapshot(tx, datasetId)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+	records = append(records, DatasetHistory{
+		Did: did, Version: last + 1, Current: true, Provenance: data})
+	return records, nil
+}
+
+// helper function to store snapshot of current provenance of a dataset
+// in history table, it should be called before dataset is changed
+func snapshotDataset(tx *sql.Tx, datasetId int64, did, createBy string) error {
+	rec, err := datasetSnapshot(tx, datasetId)
+	if err != nil {
+		msg := fmt.Sprintf("unable to get provenance of dataset %s", did)
+		return Error(err, QueryErrorCode, msg, "dbs.history.snapshotDataset")
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return Error(err, MarshalErrorCode, "", "dbs.history.snapshotDataset")
+	}
+	var version int64
+	err = tx.QueryRow(getSQL("select_dataset_history_version"), did).Scan(&version)
+	if err != nil {
+		return Error(err, QueryErrorCode, "", "dbs.history.snapshotDataset")
+	}
+	if createBy == "" {
+		createBy = "Server"
+	}
+	if Verbose > 0 {
+		log.Printf("snapshot dataset %s version %d", did, version+1)
+	}
+	_, err = tx.Exec(getSQL("insert_dataset_history"),
+		datasetId, did, version+1, string(data), Date(), createBy)
+	if err != nil {
+		return Error(err, InsertErrorCode, "", "dbs.history.snapshotDataset")
+	}
+	return nil
+}
+
+// helper function to build provenance record of a dataset within given transaction
+func datasetSnapshot(tx *sql.Tx, datasetId int64) (DatasetRecord, error) {
+	var rec DatasetRecord
+	tmpl := make(map[string]any)
+	tmpl["Owner"] = DBOWNER
+	stm, err := LoadTemplateSQL("select_provenance", tmpl)
+	if err != nil {
+		return rec, err
+	}
+	cond := fmt.Sprintf("d.dataset_id = %s", placeholder("dataset_id"))
+	stm = WhereClause(stm, []string{cond})
+	stm = fmt.Sprintf("%s ORDER BY e.environment_id, pk.package_id", stm)
+
+	// parents, files and config are part of dataset state
+	state, err := loadDatasetState(tx, datasetId)
+	if err != nil {
+		return rec, err
+	}
+	rows, err := tx.Query(stm, datasetId)
+	if err != nil {
+		return rec, err
+	}
+	defer rows.Close()
+	var builder *provenanceBuilder
+	for rows.Next() {
+		var row provenanceRow
+		if err := row.scan(rows); err != nil {
+			return rec, err
+		}
+		if builder == nil {
+			builder = newProvenanceBuilder(row, state.Relations["parent_dids"])
+		}
+		builder.add(row)
+	}
+	if err := rows.Err(); err != nil {
+		return rec, err
+	}
+	if builder == nil {
+		return rec, fmt.Errorf("no provenance found for dataset id %d", datasetId)
+	}
+	rec = builder.build()
+
+	buckets := []BucketRecord{}
+	for _, b := range rec.Buckets {
+		if b.Name != "" {
+			buckets = append(buckets, b)
+		}
+	}
+	rec.Buckets = buckets
+	for _, name := range state.Relations["input_files"] {
+		rec.InputFiles = append(rec.InputFiles, state.fileRecord("input_files", name))
+	}
+	for _, name := range state.Relations["output_files"] {
+		rec.OutputFiles = append(rec.OutputFiles, state.fileRecord("output_files", name))
+	}
+	rec.Config = state.Config
+	rec.Status = state.Status
+	return rec, nil
+}
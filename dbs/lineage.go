@@ -0,0 +1,368 @@
+package dbs
+
+// DBS lineage module
+//
+// nolint: gocyclo
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	lexicon "github.com/CHESSComputing/golib/lexicon"
+)
+
+// LineageMaxDepth defines maximum depth of lineage traversal, it is used
+// when depth is not provided and protects from cycles in parents table
+var LineageMaxDepth = 100
+
+// LineageNode represents dataset node of lineage graph
+type LineageNode struct {
+	Did        string        `json:"did"`
+	Depth      int64         `json:"depth"`
+	CreateAt   int64         `json:"create_at"`
+	CreateBy   string        `json:"create_by"`
+	ModifyAt   int64         `json:"modify_at"`
+	ModifyBy   string        `json:"modify_by"`
+	Processing string        `json:"processing,omitempty"`
+	Scripts    []string      `json:"scripts,omitempty"`
+	Files      []LineageFile `json:"files,omitempty"`
+}
+
+// LineageFile represents input or output file of lineage dataset node
+type LineageFile struct {
+	Name     string `json:"name"`
+	FileType string `json:"file_type"`
+}
+
+// LineageEdge represents parent-child edge of lineage graph
+type LineageEdge struct {
+	Parent string `json:"parent"`
+	Child  string `json:"child"`
+}
+
+// LineageRecord represents lineage graph of a given dataset
+type LineageRecord struct {
+	Did       string        `json:"did"`
+	Direction string        `json:"direction"`
+	Depth     int           `json:"depth"`
+	Nodes     []LineageNode `json:"nodes"`
+	Edges     []LineageEdge `json:"edges"`
+}
+
+// GetLineage provides lineage graph of a given dataset. The graph can be
+// rendered as JSON (default), Graphviz DOT or Mermaid via format parameter.
+func (a *API) GetLineage() error {
+	rec, err := a.lineageRecord()
+	if err != nil {
+		return Error(err, QueryErrorCode, "", "dbs.lineage.GetLineage")
+	}
+	// number of records of lineage API is number of nodes of its graph
+	count, err := getBoolParam(a.Params, "count")
+	if err != nil {
+		return Error(err, ParametersErrorCode, "", "dbs.lineage.GetLineage")
+	}
+	if count {
+		a.writeTotal(int64(len(rec.Nodes)))
+		return nil
+	}
+	format := "json"
+	if _, ok := a.Params["format"]; ok {
+		format, _ = getSingleValue(a.Params, "format")
+	}
+	labels, err := getBoolParam(a.Params, "labels")
+	if err != nil {
+		return Error(err, ParametersErrorCode, "", "dbs.lineage.GetLineage")
+	}
+	files, err := getBoolParam(a.Params, "files")
+	if err != nil {
+		return Error(err, ParametersErrorCode, "", "dbs.lineage.GetLineage")
+	}
+	if labels || files {
+		if err := lineageDetails(&rec, labels, files); err != nil {
+			return Error(err, QueryErrorCode, "", "dbs.lineage.GetLineage")
+		}
+	}
+	var data []byte
+	switch format {
+	case "json":
+		data, err = json.Marshal([]LineageRecord{rec})
+		if err != nil {
+			return Error(err, MarshalErrorCode, "", "dbs.lineage.GetLineage")
+		}
+	case "dot":
+		a.Writer.Header().Set("Content-Type", "text/vnd.graphviz")
+		data = []byte(rec.Dot(labels))
+	case "mermaid":
+		a.Writer.Header().Set("Content-Type", "text/vnd.mermaid")
+		data = []byte(rec.Mermaid(labels))
+	default:
+		msg := fmt.Sprintf("invalid format '%s', should be json, dot or mermaid", format)
+		return Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.lineage.GetLineage")
+	}
+	a.Writer.Write(data)
+	return nil
+}
+
+// helper function to parse lineage parameters and build lineage record
+func (a *API) lineageRecord() (LineageRecord, error) {
+	var rec LineageRecord
+	did, err := getSingleValue(a.Params, "did")
+	if err != nil || did == "" {
+		msg := "/lineage API requires did input"
+		return rec, Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.lineage.lineageRecord")
+	}
+	if err := lexicon.CheckPattern("did", did); err != nil {
+		msg := fmt.Sprintf("fail did validation: '%v'", did)
+		return rec, Error(err, ValidateErrorCode, msg, "dbs.lineage.lineageRecord")
+	}
+	direction := "both"
+	if _, ok := a.Params["direction"]; ok {
+		direction, _ = getSingleValue(a.Params, "direction")
+	}
+	if direction != "up" && direction != "down" && direction != "both" {
+		msg := fmt.Sprintf("invalid direction '%s', should be up, down or both", direction)
+		return rec, Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.lineage.lineageRecord")
+	}
+	depth := LineageMaxDepth
+	if _, ok := a.Params["depth"]; ok {
+		val, _ := getSingleValue(a.Params, "depth")
+		depth, err = strconv.Atoi(val)
+		if err != nil || depth < 1 {
+			msg := fmt.Sprintf("invalid depth '%s', should be positive integer", val)
+			return rec, Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.lineage.lineageRecord")
+		}
+		if depth > LineageMaxDepth {
+			depth = LineageMaxDepth
+		}
+	}
+	return getLineage(did, direction, depth)
+}
+
+// helper function to walk parents table and build lineage graph
+func getLineage(did, direction string, depth int) (LineageRecord, error) {
+	rec := LineageRecord{
+		Did:       did,
+		Direction: direction,
+		Depth:     depth,
+		Nodes:     []LineageNode{},
+		Edges:     []LineageEdge{},
+	}
+	tx, err := DB.Begin()
+	if err != nil {
+		return rec, Error(err, TransactionErrorCode, "", "dbs.lineage.getLineage")
+	}
+	defer tx.Rollback()
+
+	// root node of lineage graph
+	datasetId, err := GetID(tx, "datasets", "dataset_id", "did", did)
+	if err != nil {
+		msg := fmt.Sprintf("dataset %s is not found", did)
+		return rec, Error(err, GetIDErrorCode, msg, "dbs.lineage.getLineage")
+	}
+	root := LineageNode{Did: did}
+	stm := fmt.Sprintf(
+		"SELECT create_at, create_by, modify_at, modify_by FROM datasets WHERE dataset_id = %s",
+		placeholder("dataset_id"))
+	err = tx.QueryRow(stm, datasetId).Scan(&root.CreateAt, &root.CreateBy, &root.ModifyAt, &root.ModifyBy)
+	if err != nil {
+		return rec, Error(err, QueryErrorCode, "", "dbs.lineage.getLineage")
+	}
+	rec.Nodes = append(rec.Nodes, root)
+	nodes := map[string]bool{did: true}
+	edges := make(map[LineageEdge]bool)
+
+	for _, up := range []bool{true, false} {
+		if (up && direction == "down") || (!up && direction == "up") {
+			continue
+		}
+		tmpl := make(map[string]any)
+		tmpl["Owner"] = DBOWNER
+		tmpl["Up"] = up
+		stm, err := LoadTemplateSQL("select_lineage", tmpl)
+		if err != nil {
+			return rec, Error(err, LoadErrorCode, "", "dbs.lineage.getLineage")
+		}
+		stm = CleanStatement(stm)
+		if Verbose > 1 {
+			PrintSQL(stm, []any{datasetId, depth}, "execute")
+		}
+		rows, err := tx.Query(stm, datasetId, depth)
+		if err != nil {
+			return rec, Error(err, QueryErrorCode, "", "dbs.lineage.getLineage")
+		}
+		for rows.Next() {
+			var child, parent LineageNode
+			var level int64
+			err = rows.Scan(
+				&child.Did, &child.CreateAt, &child.CreateBy, &child.ModifyAt, &child.ModifyBy,
+				&parent.Did, &parent.CreateAt, &parent.CreateBy, &parent.ModifyAt, &parent.ModifyBy,
+				&level)
+			if err != nil {
+				rows.Close()
+				return rec, Error(err, RowsScanErrorCode, "", "dbs.lineage.getLineage")
+			}
+			// depth of the node is a distance from root dataset
+			node := parent
+			if !up {
+				node = child
+			}
+			node.Depth = level
+			if !nodes[node.Did] {
+				nodes[node.Did] = true
+				rec.Nodes = append(rec.Nodes, node)
+			}
+			edge := LineageEdge{Parent: parent.Did, Child: child.Did}
+			if !edges[edge] {
+				edges[edge] = true
+				rec.Edges = append(rec.Edges, edge)
+			}
+		}
+		if err = rows.Err(); err != nil {
+			rows.Close()
+			return rec, Error(err, RowsScanErrorCode, "", "dbs.lineage.getLineage")
+		}
+		rows.Close()
+	}
+	if Verbose > 0 {
+		log.Printf("lineage of %s: %d nodes, %d edges", did, len(rec.Nodes), len(rec.Edges))
+	}
+	return rec, nil
+}
+
+// helper function to fill processing, scripts and files of lineage nodes
+func lineageDetails(rec *LineageRecord, labels, files bool) error {
+	tx, err := DB.Begin()
+	if err != nil {
+		return Error(err, TransactionErrorCode, "", "dbs.lineage.lineageDetails")
+	}
+	defer tx.Rollback()
+	for i := range rec.Nodes {
+		node := &rec.Nodes[i]
+		if labels {
+			rows, err := tx.Query(getSQL("select_lineage_scripts"), node.Did)
+			if err != nil {
+				return Error(err, QueryErrorCode, "", "dbs.lineage.lineageDetails")
+			}
+			for rows.Next() {
+				var processing, script sql.NullString
+				var orderIdx sql.NullInt64
+				if err := rows.Scan(&processing, &script, &orderIdx); err != nil {
+					rows.Close()
+					return Error(err, RowsScanErrorCode, "", "dbs.lineage.lineageDetails")
+				}
+				node.Processing = processing.String
+				if script.Valid {
+					node.Scripts = append(node.Scripts, script.String)
+				}
+			}
+			rows.Close()
+		}
+		if files {
+			rows, err := tx.Query(getSQL("select_lineage_files"), node.Did)
+			if err != nil {
+				return Error(err, QueryErrorCode, "", "dbs.lineage.lineageDetails")
+			}
+			for rows.Next() {
+				var f LineageFile
+				if err := rows.Scan(&f.Name, &f.FileType); err != nil {
+					rows.Close()
+					return Error(err, RowsScanErrorCode, "", "dbs.lineage.lineageDetails")
+				}
+				node.Files = append(node.Files, f)
+			}
+			rows.Close()
+		}
+	}
+	return nil
+}
+
+// helper function to build edge label from processing and scripts of
+// a child dataset, i.e. the step which produced it from its parent
+func (r *LineageRecord) edgeLabel(child string) string {
+	for _, node := range r.Nodes {
+		if node.Did != child {
+			continue
+		}
+		var parts []string
+		if node.Processing != "" {
+			parts = append(parts, node.Processing)
+		}
+		if len(node.Scripts) > 0 {
+			parts = append(parts, strings.Join(node.Scripts, ", "))
+		}
+		return strings.Join(parts, "\\n")
+	}
+	return ""
+}
+
+// Dot represents lineage graph in Graphviz DOT format
+func (r *LineageRecord) Dot(labels bool) string {
+	quote := func(s string) string {
+		return fmt.Sprintf("\"%s\"", strings.Replace(s, "\"", "\\\"", -1))
+	}
+	var lines []string
+	lines = append(lines, "digraph lineage {", "  rankdir=LR;")
+	for _, node := range r.Nodes {
+		lines = append(lines, fmt.Sprintf("  %s [shape=box];", quote(node.Did)))
+	}
+	for _, edge := range r.Edges {
+		line := fmt.Sprintf("  %s -> %s", quote(edge.Parent), quote(edge.Child))
+		if label := r.edgeLabel(edge.Child); labels && label != "" {
+			line = fmt.Sprintf("%s [label=%s]", line, quote(label))
+		}
+		lines = append(lines, line+";")
+	}
+	for _, node := range r.Nodes {
+		for _, f := range node.Files {
+			lines = append(lines, fmt.Sprintf("  %s [shape=note];", quote(f.Name)))
+			if f.FileType == "input" {
+				lines = append(lines, fmt.Sprintf("  %s -> %s [style=dashed];", quote(f.Name), quote(node.Did)))
+			} else {
+				lines = append(lines, fmt.Sprintf("  %s -> %s [style=dashed];", quote(node.Did), quote(f.Name)))
+			}
+		}
+	}
+	lines = append(lines, "}")
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Mermaid represents lineage graph in Mermaid flowchart format
+func (r *LineageRecord) Mermaid(labels bool) string {
+	quote := func(s string) string {
+		s = strings.Replace(s, "\"", "#quot;", -1)
+		return fmt.Sprintf("\"%s\"", strings.Replace(s, "\\n", "<br/>", -1))
+	}
+	// mermaid node ids can not contain slashes, therefore we use generated ids
+	ids := make(map[string]string)
+	var lines []string
+	lines = append(lines, "graph LR")
+	for i, node := range r.Nodes {
+		ids[node.Did] = fmt.Sprintf("d%d", i)
+		lines = append(lines, fmt.Sprintf("  %s[%s]", ids[node.Did], quote(node.Did)))
+	}
+	for _, edge := range r.Edges {
+		arrow := "-->"
+		if label := r.edgeLabel(edge.Child); labels && label != "" {
+			arrow = fmt.Sprintf("-->|%s|", quote(label))
+		}
+		lines = append(lines, fmt.Sprintf("  %s %s %s", ids[edge.Parent], arrow, ids[edge.Child]))
+	}
+	for _, node := range r.Nodes {
+		for _, f := range node.Files {
+			if _, ok := ids[f.Name]; !ok {
+				ids[f.Name] = fmt.Sprintf("f%d", len(ids))
+				lines = append(lines, fmt.Sprintf("  %s[/%s/]", ids[f.Name], quote(f.Name)))
+			}
+			if f.FileType == "input" {
+				lines = append(lines, fmt.Sprintf("  %s -.-> %s", ids[f.Name], ids[node.Did]))
+			} else {
+				lines = append(lines, fmt.Sprintf("  %s -.-> %s", ids[node.Did], ids[f.Name]))
+			}
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -16,3 +16,20 @@ func InsertManyToMany(tx *sql.Tx, tmplName string, args ...interface{}) error {
 	}
 	return nil
 }
+
+// DeleteManyToMany provides function to delete many-to-many relationship from given
+// template name and set of parameters, it returns number of removed rows
+func DeleteManyToMany(tx *sql.Tx, tmplName string, args ...interface{}) (int64, error) {
+	stm := getSQL(tmplName)
+	res, err := tx.Exec(stm, args...)
+	if err != nil {
+		msg := fmt.Sprintf("fail to delete %s template", tmplName)
+		return 0, Error(err, DeleteErrorCode, msg, "dbs.manytomany.DeleteManyToMany")
+	}
+	nrows, err := res.RowsAffected()
+	if err != nil {
+		msg := fmt.Sprintf("fail to get number of deleted rows for %s template", tmplName)
+		return 0, Error(err, DeleteErrorCode, msg, "dbs.manytomany.DeleteManyToMany")
+	}
+	return nrows, nil
+}
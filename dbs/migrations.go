@@ -0,0 +1,342 @@
+package dbs
+
+// DBS schema migrations module
+//
+// nolint: gocyclo
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SchemaVersion defines version of DB schema required by DBS server, every
+// schema change should provide migration files in static/schema/migrations
+// area and increment this version
+var SchemaVersion = 7
+
+// BaselineSchemaVersion represents version of schema which existed before
+// schema versioning was introduced (v0.2.3), databases without
+// schema_version table are considered to be at this version
+const BaselineSchemaVersion = 1
+
+// Migration represents single schema migration
+type Migration struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	Up      string `json:"-"` // file name of upgrade statements
+	Down    string `json:"-"` // file name of rollback statements
+}
+
+// MigrationStatus represents status of schema migration in DB
+type MigrationStatus struct {
+	Version   int    `json:"version"`
+	Name      string `json:"name"`
+	Applied   bool   `json:"applied"`
+	AppliedAt int64  `json:"applied_at,omitempty"`
+}
+
+// pattern of migration file names, e.g. 0002_datasets_history.up.sql
+var migrationPattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// helper function to get area of migration files of DB back-end
+func migrationsDir() string {
+	return path.Join("schema", "migrations", DBOWNER)
+}
+
+// LoadMigrations loads ordered list of migrations of current DB back-end
+func LoadMigrations() ([]Migration, error) {
+	mdir := migrationsDir()
+	files, err := ListStaticFiles(mdir)
+	if err != nil {
+		return nil, Error(err, MigrationErrorCode, "unable to read migrations area "+mdir, "dbs.LoadMigrations")
+	}
+	migrations := make(map[int]*Migration)
+	for _, fname := range files {
+		arr := migrationPattern.FindStringSubmatch(fname)
+		if arr == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(arr[1])
+		m, ok := migrations[version]
+		if !ok {
+			m = &Migration{Version: version, Name: arr[2]}
+			migrations[version] = m
+		} else if m.Name != arr[2] {
+			msg := fmt.Sprintf("migration version %d has different names %s and %s", version, m.Name, arr[2])
+			return nil, Error(MigrationErr, MigrationErrorCode, msg, "dbs.LoadMigrations")
+		}
+		if arr[3] == "up" {
+			m.Up = fname
+		} else {
+			m.Down = fname
+		}
+	}
+	var out []Migration
+	for _, m := range migrations {
+		if m.Up == "" || m.Down == "" {
+			msg := fmt.Sprintf("migration %04d_%s should provide both up and down files", m.Version, m.Name)
+			return nil, Error(MigrationErr, MigrationErrorCode, msg, "dbs.LoadMigrations")
+		}
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	last := BaselineSchemaVersion
+	if len(out) > 0 {
+		last = out[len(out)-1].Version
+	}
+	if last != SchemaVersion {
+		msg := fmt.Sprintf("last migration version %d does not match schema version %d", last, SchemaVersion)
+		return nil, Error(MigrationErr, MigrationErrorCode, msg, "dbs.LoadMigrations")
+	}
+	return out, nil
+}
+
+// CurrentSchemaVersion provides version of DB schema, databases without
+// schema_version table are at baseline version while empty databases
+// without DBS tables are at version zero
+func CurrentSchemaVersion() int {
+	version, _ := schemaVersion()
+	return version
+}
+
+// helper function to get version of DB schema and flag if DB has
+// schema_version table
+func schemaVersion() (int, bool) {
+	var version int
+	err := DB.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&version)
+	if err == nil {
+		return version, true
+	}
+	if Verbose > 0 {
+		log.Println("unable to read schema_version table", err)
+	}
+	var count int64
+	if e := DB.QueryRow("SELECT COUNT(*) FROM datasets").Scan(&count); e != nil {
+		return 0, false
+	}
+	return BaselineSchemaVersion, false
+}
+
+// CheckSchemaVersion checks that DB schema is not older than the one
+// required by DBS server
+func CheckSchemaVersion() error {
+	version := CurrentSchemaVersion()
+	if version < SchemaVersion {
+		msg := fmt.Sprintf("database schema version %d is older than required version %d, please run migration (srv -migrate)",
+			version, SchemaVersion)
+		return Error(MigrationErr, MigrationErrorCode, msg, "dbs.CheckSchemaVersion")
+	}
+	if version > SchemaVersion {
+		log.Printf("WARNING: database schema version %d is newer than server schema version %d", version, SchemaVersion)
+	}
+	return nil
+}
+
+// GetMigrationStatus provides status of all known schema migrations
+func GetMigrationStatus() ([]MigrationStatus, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	current := CurrentSchemaVersion()
+	applied := make(map[int]int64)
+	if rows, err := DB.Query(getSQL("select_schema_version")); err == nil {
+		defer rows.Close()
+		for rows.Next() {
+			var version int
+			var name string
+			var appliedAt int64
+			if err := rows.Scan(&version, &name, &appliedAt); err != nil {
+				return nil, Error(err, RowsScanErrorCode, "", "dbs.GetMigrationStatus")
+			}
+			applied[version] = appliedAt
+		}
+	}
+	out := []MigrationStatus{{
+		Version:   BaselineSchemaVersion,
+		Name:      "baseline",
+		Applied:   current >= BaselineSchemaVersion,
+		AppliedAt: applied[BaselineSchemaVersion],
+	}}
+	for _, m := range migrations {
+		tstamp, ok := applied[m.Version]
+		out = append(out, MigrationStatus{
+			Version: m.Version, Name: m.Name, Applied: ok, AppliedAt: tstamp})
+	}
+	return out, nil
+}
+
+// Migrate applies all pending schema migrations, every migration is
+// applied within its own transaction and recorded in schema_version table
+func Migrate() ([]Migration, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	current, versioned := schemaVersion()
+	if current == 0 {
+		msg := fmt.Sprintf("database does not contain DBS schema, please create it from static/schema/%s.sql", DBOWNER)
+		return nil, Error(MigrationErr, MigrationErrorCode, msg, "dbs.Migrate")
+	}
+	if !versioned {
+		// record baseline version of database without schema versioning
+		if _, err := DB.Exec(getSQL("create_schema_version")); err != nil {
+			return nil, Error(err, MigrationErrorCode, "unable to create schema_version table", "dbs.Migrate")
+		}
+		_, err := DB.Exec(getSQL("insert_schema_version"), BaselineSchemaVersion, "baseline", Date())
+		if err != nil {
+			return nil, Error(err, MigrationErrorCode, "unable to record baseline version", "dbs.Migrate")
+		}
+	}
+	var applied []Migration
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		if err := applyMigration(m, m.Up, true); err != nil {
+			return applied, err
+		}
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+// Rollback reverts last applied schema migration
+func Rollback() (Migration, error) {
+	var migration Migration
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return migration, err
+	}
+	current := CurrentSchemaVersion()
+	if current <= BaselineSchemaVersion {
+		msg := fmt.Sprintf("database schema version %d can't be rolled back", current)
+		return migration, Error(MigrationErr, MigrationErrorCode, msg, "dbs.Rollback")
+	}
+	for _, m := range migrations {
+		if m.Version == current {
+			return m, applyMigration(m, m.Down, false)
+		}
+	}
+	msg := fmt.Sprintf("unknown migration of database schema version %d", current)
+	return migration, Error(MigrationErr, MigrationErrorCode, msg, "dbs.Rollback")
+}
+
+// helper function to apply statements of migration file and record it in
+// schema_version table. Please note that MySQL commits DDL statements
+// implicitly, therefore failed MySQL migration may require manual cleanup.
+func applyMigration(m Migration, fname string, upgrade bool) error {
+	data, err := ReadStaticFile(path.Join(migrationsDir(), fname))
+	if err != nil {
+		return Error(err, MigrationErrorCode, "unable to read migration "+fname, "dbs.applyMigration")
+	}
+	tx, err := DB.Begin()
+	if err != nil {
+		return Error(err, TransactionErrorCode, "", "dbs.applyMigration")
+	}
+	defer tx.Rollback()
+	for _, stm := range SplitStatements(string(data)) {
+		if Verbose > 0 {
+			log.Printf("migration %s: %s", fname, stm)
+		}
+		if _, err := tx.Exec(stm); err != nil {
+			msg := fmt.Sprintf("unable to apply migration %s, statement %s", fname, stm)
+			return Error(err, MigrationErrorCode, msg, "dbs.applyMigration")
+		}
+	}
+	if upgrade {
+		_, err = tx.Exec(getSQL("insert_schema_version"), m.Version, m.Name, Date())
+	} else {
+		_, err = tx.Exec(getSQL("delete_schema_version"), m.Version)
+	}
+	if err != nil {
+		return Error(err, MigrationErrorCode, "unable to update schema_version table", "dbs.applyMigration")
+	}
+	if err := tx.Commit(); err != nil {
+		return Error(err, CommitErrorCode, "", "dbs.applyMigration")
+	}
+	return nil
+}
+
+// Bootstrap creates the latest DBS schema in empty database from schema file
+// of DB back-end, e.g. static/schema/sqlite.sql, database which already has
+// DBS schema is left untouched. It returns true if schema was created.
+func Bootstrap() (bool, error) {
+	if CurrentSchemaVersion() != 0 {
+		return false, nil
+	}
+	fname := path.Join("schema", DBOWNER+".sql")
+	data, err := ReadStaticFile(fname)
+	if err != nil {
+		msg := fmt.Sprintf("unable to read schema %s of %s back-end", fname, DBOWNER)
+		return false, Error(err, MigrationErrorCode, msg, "dbs.Bootstrap")
+	}
+	tx, err := DB.Begin()
+	if err != nil {
+		return false, Error(err, TransactionErrorCode, "", "dbs.Bootstrap")
+	}
+	defer tx.Rollback()
+	for _, stm := range SplitStatements(string(data)) {
+		if Verbose > 0 {
+			log.Printf("bootstrap %s: %s", fname, stm)
+		}
+		if _, err := tx.Exec(stm); err != nil {
+			msg := fmt.Sprintf("unable to create schema, statement %s", stm)
+			return false, Error(err, MigrationErrorCode, msg, "dbs.Bootstrap")
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return false, Error(err, CommitErrorCode, "", "dbs.Bootstrap")
+	}
+	return true, nil
+}
+
+// SplitStatements splits content of SQL file into list of statements.
+// Statements are terminated by semicolon at the end of line, BEGIN ... END
+// blocks of triggers and $$ quoted bodies of PostgreSQL functions are kept
+// within single statement.
+func SplitStatements(content string) []string {
+	var out []string
+	var lines []string
+	inBlock := false
+	inQuote := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || (!inQuote && strings.HasPrefix(trimmed, "--")) {
+			continue
+		}
+		lines = append(lines, line)
+		if strings.Count(line, "$$")%2 == 1 {
+			inQuote = !inQuote
+		}
+		if inQuote {
+			continue
+		}
+		upper := strings.ToUpper(trimmed)
+		if upper == "BEGIN" {
+			inBlock = true
+			continue
+		}
+		if inBlock {
+			if upper == "END;" {
+				inBlock = false
+			} else {
+				continue
+			}
+		} else if !strings.HasSuffix(trimmed, ";") {
+			continue
+		}
+		stm := strings.TrimSuffix(strings.TrimSpace(strings.Join(lines, "\n")), ";")
+		out = append(out, stm)
+		lines = nil
+	}
+	if stm := strings.TrimSpace(strings.Join(lines, "\n")); stm != "" {
+		out = append(out, stm)
+	}
+	return out
+}
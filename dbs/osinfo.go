@@ -47,7 +47,7 @@ func (a *API) GetOsInfo() error {
 	stm = WhereClause(stm, conds)
 
 	// use generic query API to fetch the results from DB
-	err = executeAll(a.Writer, a.Separator, stm, args...)
+	err = a.executePage(stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "", "dbs.osinfo.OsInfo")
 	}
@@ -120,7 +120,8 @@ func (r *OsInfo) Insert(tx *sql.Tx) (int64, error) {
 	} else if Verbose > 1 {
 		log.Printf("Insert OsInfo\n%s\n%+v", stm, r)
 	}
-	_, err = tx.Exec(
+	r.OS_ID, err = execInsert(
+		tx,
 		stm,
 		r.OS_ID,
 		r.NAME,
@@ -47,7 +47,7 @@ func (a *API) GetPackage() error {
 	stm = WhereClause(stm, conds)
 
 	// use generic query API to fetch the results from DB
-	err = executeAll(a.Writer, a.Separator, stm, args...)
+	err = a.executePage(stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "", "dbs.packages.Packages")
 	}
@@ -142,7 +142,8 @@ func (r *Packages) Insert(tx *sql.Tx) (int64, error) {
 	} else if Verbose > 1 {
 		log.Printf("Insert Packages\n%s\n%+v", stm, r)
 	}
-	_, err = tx.Exec(
+	r.PACKAGE_ID, err = execInsert(
+		tx,
 		stm,
 		r.PACKAGE_ID,
 		r.NAME,
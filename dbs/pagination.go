@@ -0,0 +1,184 @@
+package dbs
+
+// DBS pagination module
+//
+// nolint: gocyclo
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/CHESSComputing/golib/utils"
+)
+
+// PaginationKeys lists parameters used for pagination, sorting and counting
+// of records of GET APIs
+var PaginationKeys = []string{"idx", "limit", "sort", "count"}
+
+// timestamps columns shared by most of DBS tables
+var timestampKeys = []string{"create_at", "create_by", "modify_at", "modify_by"}
+
+// SortKeys defines whitelist of sort keys (output columns) per DBS API,
+// the first key is used as default sort key for paginated queries
+var SortKeys = map[string][]string{
+	"dataset":     append([]string{"did", "status"}, timestampKeys...),
+	"file":        append([]string{"name", "did", "checksum", "size", "is_file_valid", "file_type"}, timestampKeys...),
+	"child":       append([]string{"child_did", "did"}, timestampKeys...),
+	"parent":      append([]string{"parent_did", "did"}, timestampKeys...),
+	"osinfo":      append([]string{"did", "osinfo_name", "osinfo_version", "osinfo_kernel"}, timestampKeys...),
+	"environment": append([]string{"did", "processing", "environment_name", "environment_version"}, timestampKeys...),
+	"script":      append([]string{"did", "processing", "script_name", "order_idx"}, timestampKeys...),
+	"config":      append([]string{"did"}, timestampKeys...),
+	"package":     {"did", "environment_name", "package_name", "package_version"},
+	"bucket":      append([]string{"bucket", "bucket_id", "uuid", "dataset_id"}, timestampKeys...),
+	"site":        append([]string{"site", "site_id"}, timestampKeys...),
+	"processing":  append([]string{"processing", "processing_id"}, timestampKeys...),
+	"provenance":  {"did"},
+	"search":      append([]string{"did", "status"}, timestampKeys...),
+	"audit":       {"audit_id", "api", "method", "create_by", "remote_addr", "result", "code", "create_at"},
+}
+
+// Pagination represents pagination and sorting parameters of GET APIs
+type Pagination struct {
+	Idx   int      // index of first record to return
+	Limit int      // max number of records to return, 0 means no limit
+	Sort  []string // list of ORDER BY expressions
+}
+
+// getPagination parses pagination and sorting parameters of given API
+func (a *API) getPagination() (Pagination, error) {
+	var page Pagination
+	var err error
+	if _, ok := a.Params["idx"]; ok {
+		val, _ := getSingleValue(a.Params, "idx")
+		page.Idx, err = strconv.Atoi(val)
+		if err != nil || page.Idx < 0 {
+			msg := fmt.Sprintf("invalid idx '%s', should be non-negative integer", val)
+			return page, Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.getPagination")
+		}
+	}
+	if _, ok := a.Params["limit"]; ok {
+		val, _ := getSingleValue(a.Params, "limit")
+		page.Limit, err = strconv.Atoi(val)
+		if err != nil || page.Limit < 1 {
+			msg := fmt.Sprintf("invalid limit '%s', should be positive integer", val)
+			return page, Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.getPagination")
+		}
+	}
+	if page.Idx > 0 && page.Limit == 0 {
+		msg := "idx parameter requires limit"
+		return page, Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.getPagination")
+	}
+	keys := SortKeys[a.Api]
+	for _, val := range getValues(a.Params, "sort") {
+		for _, item := range strings.Split(val, ",") {
+			arr := strings.Split(strings.TrimSpace(item), ":")
+			key, order := arr[0], "asc"
+			if len(arr) == 2 {
+				order = strings.ToLower(arr[1])
+			}
+			if len(arr) > 2 || (order != "asc" && order != "desc") {
+				msg := fmt.Sprintf("invalid sort '%s', should be field:asc|desc", item)
+				return page, Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.getPagination")
+			}
+			if !utils.InList(key, keys) {
+				msg := fmt.Sprintf("invalid sort field '%s', allowed fields: %v", key, keys)
+				return page, Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.getPagination")
+			}
+			page.Sort = append(page.Sort, fmt.Sprintf("%s %s", key, strings.ToUpper(order)))
+		}
+	}
+	// paginated results should have stable order
+	if page.Limit > 0 && len(page.Sort) == 0 && len(keys) > 0 {
+		page.Sort = append(page.Sort, fmt.Sprintf("%s ASC", keys[0]))
+	}
+	return page, nil
+}
+
+// Apply adds ORDER BY and LIMIT/OFFSET clauses to given SQL statement
+func (p *Pagination) Apply(stm string) string {
+	stm = strings.TrimSuffix(strings.TrimSpace(stm), ";")
+	if len(p.Sort) > 0 {
+		stm = fmt.Sprintf("%s ORDER BY %s", stm, strings.Join(p.Sort, ", "))
+	}
+	if p.Limit > 0 {
+		stm = fmt.Sprintf("%s LIMIT %d OFFSET %d", stm, p.Limit, p.Idx)
+	}
+	return stm
+}
+
+// helper function to set total count and next page headers of paginated query
+func (a *API) setPageHeaders(page Pagination, stm string, args ...interface{}) error {
+	if page.Limit == 0 {
+		return nil
+	}
+	total, err := countRecords(stm, args...)
+	if err != nil {
+		return err
+	}
+	a.Writer.Header().Set("X-Total-Count", fmt.Sprintf("%d", total))
+	if next := page.Idx + page.Limit; int64(next) < total {
+		a.Writer.Header().Set("X-Next-Idx", fmt.Sprintf("%d", next))
+	}
+	return nil
+}
+
+// helper function to write number of records of given SQL statement
+func (a *API) writeCount(stm string, args ...interface{}) error {
+	total, err := countRecords(stm, args...)
+	if err != nil {
+		return err
+	}
+	a.writeTotal(total)
+	return nil
+}
+
+// helper function to write given number of records
+func (a *API) writeTotal(total int64) {
+	a.Writer.Header().Set("X-Total-Count", fmt.Sprintf("%d", total))
+	data := []byte(fmt.Sprintf("{\"count\":%d}\n", total))
+	if a.Separator != "" {
+		data = []byte(fmt.Sprintf("[{\"count\":%d}]\n", total))
+	}
+	a.Writer.Write(data)
+}
+
+// helper function to count number of records of given SQL statement, since
+// statements select DISTINCT rows it is equivalent to COUNT(DISTINCT ...)
+// over all output columns and works across all DB back-ends
+func countRecords(stm string, args ...interface{}) (int64, error) {
+	stm = strings.TrimSuffix(strings.TrimSpace(CleanStatement(stm)), ";")
+	stm = fmt.Sprintf("SELECT COUNT(*) FROM (%s) T", stm)
+	if Verbose > 1 {
+		PrintSQL(stm, args, "count")
+	}
+	var total int64
+	if err := DB.QueryRow(stm, args...).Scan(&total); err != nil {
+		log.Printf("unable to count records, query %s, error %v", stm, err)
+		return 0, Error(err, QueryErrorCode, "", "dbs.countRecords")
+	}
+	return total, nil
+}
+
+// executePage executes given statement with pagination and sorting
+// parameters of the API and writes results to API writer. If count
+// parameter is provided only number of matching records is written.
+func (a *API) executePage(stm string, args ...interface{}) error {
+	count, err := getBoolParam(a.Params, "count")
+	if err != nil {
+		return err
+	}
+	if count {
+		return a.writeCount(stm, args...)
+	}
+	page, err := a.getPagination()
+	if err != nil {
+		return err
+	}
+	if err := a.setPageHeaders(page, stm, args...); err != nil {
+		return err
+	}
+	return executeAll(a.Writer, a.Separator, page.Apply(stm), args...)
+}
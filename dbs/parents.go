@@ -27,8 +27,29 @@ type Parents struct {
 
 // ParentRecord represents input parent record from HTTP request
 type ParentRecord struct {
-	Parent string `json:"parent" validate:"required"`
-	Did    string `json:"did" validate:"required"`
+	Parent  string   `json:"parent"`
+	Parents []string `json:"parent_dids"`
+	Did     string   `json:"did" validate:"required"`
+}
+
+// ParentDids returns unique list of parent dids of the record
+func (r *ParentRecord) ParentDids() []string {
+	return parentDids(r.Parent, r.Parents)
+}
+
+// Validate implementation of ParentRecord
+func (r *ParentRecord) Validate() error {
+	if err := lexicon.CheckPattern("did", r.Did); err != nil {
+		msg := fmt.Sprintf("fail did validation: '%v'", r.Did)
+		return Error(err, ValidateErrorCode, msg, "dbs.ParentRecord.Validate")
+	}
+	for _, parent := range r.ParentDids() {
+		if err := lexicon.CheckPattern("dataset_parent", parent); err != nil {
+			msg := fmt.Sprintf("fail parent validation: '%v'", parent)
+			return Error(err, ValidateErrorCode, msg, "dbs.ParentRecord.Validate")
+		}
+	}
+	return nil
 }
 
 // Parents DBS API
@@ -57,55 +78,39 @@ func (a *API) GetParent() error {
 	stm = WhereClause(stm, conds)
 
 	// use generic query API to fetch the results from DB
-	err = executeAll(a.Writer, a.Separator, stm, args...)
+	err = a.executePage(stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "", "dbs.parents.GetParent")
 	}
 	return nil
 }
 
-// InsertParent inserts parent record into DB
+// InsertParent inserts parent record(s) into DB
 func (a *API) InsertParent() error {
-	// read given input
-	data, err := io.ReadAll(a.Reader)
+	rec, err := a.decodeParentRecord()
 	if err != nil {
-		log.Println("fail to read data", err)
-		return Error(err, ReaderErrorCode, "", "dbs.parents.InsertParent")
-	}
-	rec := ParentRecord{}
-	if a.ContentType == "application/json" {
-		err = json.Unmarshal(data, &rec)
-	} else {
-		log.Println("Parser dataset record using default application/json mtime")
-		err = json.Unmarshal(data, &rec)
+		return Error(err, DecodeErrorCode, "", "dbs.parents.InsertParent")
 	}
-	if err != nil {
-		log.Println("reading", a.ContentType)
-		log.Println("reading data", string(data))
-		log.Println("fail to decode data", err)
-		return Error(err, UnmarshalErrorCode, "", "dbs.parents.InsertParent")
+	if len(rec.ParentDids()) == 0 {
+		msg := "no parent did is provided"
+		return Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.parents.InsertParent")
 	}
 	// find parent id and current did
 	tx, err := DB.Begin()
 	if err != nil {
-		return Error(err, TransactionErrorCode, "", "dbs.insertRecord")
+		return Error(err, TransactionErrorCode, "", "dbs.parents.InsertParent")
 	}
 	defer tx.Rollback()
 	datasetId, err := GetID(tx, "datasets", "dataset_id", "did", rec.Did)
 	if err != nil {
-		return Error(err, LoadErrorCode, "", "dbs.parents.InsertRecord")
+		msg := fmt.Sprintf("dataset %s is not found", rec.Did)
+		return Error(err, GetIDErrorCode, msg, "dbs.parents.InsertParent")
 	}
-	parentId, err := GetID(tx, "datasets", "dataset_id", "did", rec.Parent)
+	err = snapshotDataset(tx, datasetId, rec.Did, a.CreateBy)
 	if err != nil {
-		return Error(err, LoadErrorCode, "", "dbs.parents.InsertRecord")
-	}
-	// the API provides Reader which will be used by Decode function to load the HTTP payload
-	// and cast it to Parents data structure
-	record := Parents{
-		PARENT_ID:  parentId,
-		DATASET_ID: datasetId,
+		return Error(err, ParentsErrorCode, "", "dbs.API.InsertParent")
 	}
-	_, err = record.Insert(tx)
+	err = insertParents(tx, datasetId, rec.Did, rec.ParentDids(), a.CreateBy)
 	if err != nil {
 		msg := "unable to insert Parents record"
 		return Error(err, ParentsErrorCode, msg, "dbs.API.InsertParent")
@@ -118,18 +123,171 @@ func (a *API) InsertParent() error {
 	return nil
 }
 
-// UpdateParent inserts parent record in DB
+// UpdateParent replaces entire set of parents of a dataset in DB
 func (a *API) UpdateParent() error {
+	rec, err := a.decodeParentRecord()
+	if err != nil {
+		return Error(err, DecodeErrorCode, "", "dbs.parents.UpdateParent")
+	}
+	tx, err := DB.Begin()
+	if err != nil {
+		return Error(err, TransactionErrorCode, "", "dbs.parents.UpdateParent")
+	}
+	defer tx.Rollback()
+	datasetId, err := GetID(tx, "datasets", "dataset_id", "did", rec.Did)
+	if err != nil {
+		msg := fmt.Sprintf("dataset %s is not found", rec.Did)
+		return Error(err, GetIDErrorCode, msg, "dbs.parents.UpdateParent")
+	}
+	err = snapshotDataset(tx, datasetId, rec.Did, a.CreateBy)
+	if err != nil {
+		return Error(err, ParentsErrorCode, "", "dbs.API.UpdateParent")
+	}
+	// remove existing parent links and insert new ones
+	_, err = DeleteManyToMany(tx, "delete_dataset_parent", datasetId)
+	if err != nil {
+		msg := "unable to delete Parents records"
+		return Error(err, ParentsErrorCode, msg, "dbs.API.UpdateParent")
+	}
+	err = insertParents(tx, datasetId, rec.Did, rec.ParentDids(), a.CreateBy)
+	if err != nil {
+		msg := "unable to insert Parents record"
+		return Error(err, ParentsErrorCode, msg, "dbs.API.UpdateParent")
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "unable to commit Parents record"
+		return Error(err, ParentsErrorCode, msg, "dbs.API.UpdateParent")
+	}
 	return nil
 }
 
-// DeleteParent deletes parent record in DB
+// DeleteParent deletes parent link of a dataset in DB
 func (a *API) DeleteParent() error {
+	did, err := getSingleValue(a.Params, "did")
+	if err != nil || did == "" {
+		msg := "no dataset did is provided"
+		return Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.parents.DeleteParent")
+	}
+	parent, err := getSingleValue(a.Params, "parent")
+	if err != nil || parent == "" {
+		msg := "no parent did is provided"
+		return Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.parents.DeleteParent")
+	}
+	tx, err := DB.Begin()
+	if err != nil {
+		return Error(err, TransactionErrorCode, "", "dbs.parents.DeleteParent")
+	}
+	defer tx.Rollback()
+	datasetId, err := GetID(tx, "datasets", "dataset_id", "did", did)
+	if err != nil {
+		msg := fmt.Sprintf("dataset %s is not found", did)
+		return Error(err, GetIDErrorCode, msg, "dbs.parents.DeleteParent")
+	}
+	parentId, err := GetID(tx, "datasets", "dataset_id", "did", parent)
+	if err != nil {
+		msg := fmt.Sprintf("parent dataset %s is not found", parent)
+		return Error(err, GetIDErrorCode, msg, "dbs.parents.DeleteParent")
+	}
+	err = snapshotDataset(tx, datasetId, did, a.CreateBy)
+	if err != nil {
+		return Error(err, ParentsErrorCode, "", "dbs.API.DeleteParent")
+	}
+	record := Parents{PARENT_ID: parentId, DATASET_ID: datasetId}
+	err = record.Delete(tx)
+	if err != nil {
+		msg := fmt.Sprintf("unable to delete parent %s of dataset %s", parent, did)
+		return Error(err, ParentsErrorCode, msg, "dbs.API.DeleteParent")
+	}
+	err = tx.Commit()
+	if err != nil {
+		msg := "unable to commit Parents record"
+		return Error(err, ParentsErrorCode, msg, "dbs.API.DeleteParent")
+	}
+	return nil
+}
+
+// helper function to decode and validate parent record from API reader
+func (a *API) decodeParentRecord() (ParentRecord, error) {
+	rec := ParentRecord{}
+	data, err := io.ReadAll(a.Reader)
+	if err != nil {
+		log.Println("fail to read data", err)
+		return rec, Error(err, ReaderErrorCode, "", "dbs.parents.decodeParentRecord")
+	}
+	err = json.Unmarshal(data, &rec)
+	if err != nil {
+		log.Println("reading data", string(data))
+		log.Println("fail to decode data", err)
+		return rec, Error(err, UnmarshalErrorCode, "", "dbs.parents.decodeParentRecord")
+	}
+	err = rec.Validate()
+	if err != nil {
+		return rec, Error(err, ValidateErrorCode, "", "dbs.parents.decodeParentRecord")
+	}
+	return rec, nil
+}
+
+// helper function to insert parent links of a given dataset, every parent
+// dataset must exist in datasets table
+func insertParents(tx *sql.Tx, datasetId int64, did string, parents []string, createBy string) error {
+	for _, parent := range parents {
+		if parent == did {
+			msg := fmt.Sprintf("dataset %s can not be parent of itself", did)
+			return Error(InvalidParamErr, ParentsErrorCode, msg, "dbs.parents.insertParents")
+		}
+		parentId, err := GetID(tx, "datasets", "dataset_id", "did", parent)
+		if err != nil || parentId == 0 {
+			msg := fmt.Sprintf("parent dataset %s is not found", parent)
+			return Error(err, GetIDErrorCode, msg, "dbs.parents.insertParents")
+		}
+		// skip already existing relationships
+		attrs := []string{"parent_id", "dataset_id"}
+		if IfExistMulti(tx, "parents", "parent_id", attrs, parentId, datasetId) {
+			continue
+		}
+		record := Parents{
+			PARENT_ID:  parentId,
+			DATASET_ID: datasetId,
+			CREATE_BY:  createBy,
+			MODIFY_BY:  createBy,
+		}
+		if _, err = record.Insert(tx); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// helper function to get unique list of parent dids from single parent
+// and list of parents
+func parentDids(parent string, parents []string) []string {
+	var out []string
+	if parent != "" {
+		out = append(out, parent)
+	}
+	for _, p := range parents {
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return UniqueList(out)
+}
+
 // Delete implementation of Parents
 func (r *Parents) Delete(tx *sql.Tx) error {
+	stm := getSQL("delete_parent")
+	if Verbose > 0 {
+		log.Printf("Delete Parents\n%s\n%+v", stm, r)
+	}
+	res, err := tx.Exec(stm, r.DATASET_ID, r.PARENT_ID)
+	if err != nil {
+		return Error(err, DeleteErrorCode, "", "dbs.parents.Delete")
+	}
+	if nrows, err := res.RowsAffected(); err == nil && nrows == 0 {
+		msg := "parent relationship is not found"
+		return Error(InvalidParamErr, ParentsErrorCode, msg, "dbs.parents.Delete")
+	}
 	return nil
 }
 
@@ -141,13 +299,9 @@ func (r *Parents) Update(tx *sql.Tx) error {
 // Insert implementation of Parents
 func (r *Parents) Insert(tx *sql.Tx) (int64, error) {
 	var err error
-	if r.PARENT_ID == 0 {
-		parentID, err := getNextId(tx, "parents", "parent_id")
-		if err != nil {
-			log.Println("unable to get parentID", err)
-			return 0, Error(err, ParentsErrorCode, "", "dbs.parents.Insert")
-		}
-		r.PARENT_ID = parentID
+	if r.PARENT_ID == 0 || r.DATASET_ID == 0 {
+		msg := "parent relationship requires ids of parent and child datasets"
+		return 0, Error(InvalidParamErr, ParentsErrorCode, msg, "dbs.parents.Insert")
 	}
 	// set defaults and validate the record
 	r.SetDefaults()
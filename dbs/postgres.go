@@ -0,0 +1,154 @@
+package dbs
+
+// DBS PostgreSQL module
+//
+// DBS builds SQL statements with positional ? placeholders (see placeholder
+// and ReplaceBinds functions) while PostgreSQL expects $1, $2, ... binds.
+// Instead of handling PostgreSQL at every call site we register database
+// driver which wraps lib/pq driver and rebinds placeholders of every
+// statement sent to PostgreSQL.
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// PostgresDriver defines name of database driver used for PostgreSQL back-end
+const PostgresDriver = "dbs-postgres"
+
+func init() {
+	sql.Register(PostgresDriver, &pgDriver{})
+}
+
+// DriverName provides name of database driver for given DB type, PostgreSQL
+// connections should use PostgresDriver which rebinds statement placeholders
+func DriverName(dbtype string) string {
+	switch strings.ToLower(dbtype) {
+	case "postgres", "postgresql", "pq":
+		return PostgresDriver
+	}
+	return dbtype
+}
+
+// Rebind converts ? placeholders of given SQL statement into PostgreSQL
+// $n placeholders, placeholders within quoted literals and identifiers are
+// left untouched
+func Rebind(stm string) string {
+	if !strings.Contains(stm, "?") {
+		return stm
+	}
+	var out strings.Builder
+	var quote rune
+	idx := 0
+	for _, c := range stm {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			idx++
+			out.WriteString(fmt.Sprintf("$%d", idx))
+			continue
+		}
+		out.WriteRune(c)
+	}
+	return out.String()
+}
+
+// pgDriver wraps lib/pq driver and provides connections which rebind
+// placeholders of SQL statements
+type pgDriver struct {
+	pq.Driver
+}
+
+// Open implements driver.Driver interface
+func (d *pgDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &pgConn{conn}, nil
+}
+
+// pgConn represents PostgreSQL connection, it implements all optional
+// interfaces of lib/pq connection to keep its behavior intact
+type pgConn struct {
+	driver.Conn
+}
+
+// Prepare implements driver.Conn interface
+func (c *pgConn) Prepare(query string) (driver.Stmt, error) {
+	return c.Conn.Prepare(Rebind(query))
+}
+
+// PrepareContext implements driver.ConnPrepareContext interface
+func (c *pgConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if pc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return pc.PrepareContext(ctx, Rebind(query))
+	}
+	return c.Prepare(query)
+}
+
+// QueryContext implements driver.QueryerContext interface
+func (c *pgConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if qc, ok := c.Conn.(driver.QueryerContext); ok {
+		return qc.QueryContext(ctx, Rebind(query), args)
+	}
+	return nil, driver.ErrSkip
+}
+
+// ExecContext implements driver.ExecerContext interface
+func (c *pgConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if ec, ok := c.Conn.(driver.ExecerContext); ok {
+		return ec.ExecContext(ctx, Rebind(query), args)
+	}
+	return nil, driver.ErrSkip
+}
+
+// BeginTx implements driver.ConnBeginTx interface
+func (c *pgConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if bc, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return bc.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+// Ping implements driver.Pinger interface
+func (c *pgConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// CheckNamedValue implements driver.NamedValueChecker interface
+func (c *pgConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// ResetSession implements driver.SessionResetter interface
+func (c *pgConn) ResetSession(ctx context.Context) error {
+	if sr, ok := c.Conn.(driver.SessionResetter); ok {
+		return sr.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid implements driver.Validator interface
+func (c *pgConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
@@ -41,7 +41,7 @@ func (a *API) GetProcessing() error {
 	stm = WhereClause(stm, conds)
 
 	// use generic query API to fetch the results from DB
-	err = executeAll(a.Writer, a.Separator, stm, args...)
+	err = a.executePage(stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "", "dbs.processing.Processing")
 	}
@@ -100,7 +100,8 @@ func (r *Processing) Insert(tx *sql.Tx) (int64, error) {
 	} else if Verbose > 1 {
 		log.Printf("Insert Processing\n%s\n%+v", stm, r)
 	}
-	_, err = tx.Exec(
+	r.PROCESSING_ID, err = execInsert(
+		tx,
 		stm,
 		r.PROCESSING_ID,
 		r.PROCESSING,
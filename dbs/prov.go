@@ -0,0 +1,333 @@
+package dbs
+
+// DBS W3C PROV module
+// PROV data model: https://www.w3.org/TR/prov-dm/
+// PROV-JSON: https://www.w3.org/submissions/prov-json/
+// PROV-O: https://www.w3.org/TR/prov-o/
+//
+// nolint: gocyclo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ProvNamespace defines namespace of DBS identifiers used in PROV records
+var ProvNamespace = "https://chess.cornell.edu/dbs/"
+
+// ProvFormats lists supported W3C PROV export formats and their content types
+var ProvFormats = map[string]string{
+	"prov-json": "application/provenance+json",
+	"turtle":    "text/turtle",
+	"jsonld":    "application/ld+json",
+}
+
+// namespaces used by PROV records
+const (
+	provNS = "http://www.w3.org/ns/prov#"
+	rdfsNS = "http://www.w3.org/2000/01/rdf-schema#"
+	xsdNS  = "http://www.w3.org/2001/XMLSchema#"
+)
+
+// ProvNode represents PROV entity, activity or agent
+type ProvNode struct {
+	Id    string            // identifier local to DBS namespace
+	Kind  string            // entity, activity or agent
+	Type  string            // DBS type of the node, e.g. dbs:Dataset
+	Label string            // human readable label
+	Time  int64             // generation time of entities
+	Attrs map[string]string // additional attributes
+}
+
+// ProvRelation represents PROV relation between two nodes
+type ProvRelation struct {
+	Kind    string // used, wasGeneratedBy, wasDerivedFrom, etc.
+	Subject string // node id of relation subject
+	Object  string // node id of relation object
+}
+
+// ProvDocument represents PROV document of DBS dataset
+type ProvDocument struct {
+	Nodes     []ProvNode
+	Relations []ProvRelation
+	ids       map[string]bool
+}
+
+// PROV-JSON attribute names of relation subject and object
+var provJsonRelations = map[string][2]string{
+	"used":              {"prov:activity", "prov:entity"},
+	"wasGeneratedBy":    {"prov:entity", "prov:activity"},
+	"wasDerivedFrom":    {"prov:generatedEntity", "prov:usedEntity"},
+	"wasAssociatedWith": {"prov:activity", "prov:agent"},
+	"wasAttributedTo":   {"prov:entity", "prov:agent"},
+	"wasInformedBy":     {"prov:informed", "prov:informant"},
+}
+
+// GetProv provides W3C PROV representation of dataset provenance
+func (a *API) GetProv(did, format string) error {
+	ctype, ok := ProvFormats[format]
+	if !ok {
+		msg := fmt.Sprintf("unsupported provenance format '%s'", format)
+		return Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.prov.GetProv")
+	}
+	doc, err := provDocument(did)
+	if err != nil {
+		return Error(err, ProvenanceErrorCode, "", "dbs.prov.GetProv")
+	}
+	var data []byte
+	switch format {
+	case "prov-json":
+		data, err = json.MarshalIndent(doc.ProvJson(), "", "  ")
+	case "jsonld":
+		data, err = json.MarshalIndent(doc.JsonLD(), "", "  ")
+	case "turtle":
+		data = []byte(doc.Turtle())
+	}
+	if err != nil {
+		return Error(err, MarshalErrorCode, "", "dbs.prov.GetProv")
+	}
+	a.Writer.Header().Set("Content-Type", ctype)
+	a.Writer.Write(data)
+	return nil
+}
+
+// helper function to build PROV document from dataset lineage
+func provDocument(did string) (ProvDocument, error) {
+	doc := ProvDocument{ids: make(map[string]bool)}
+	rec, err := getLineage(did, "up", 1)
+	if err != nil {
+		return doc, err
+	}
+	if err := lineageDetails(&rec, true, true); err != nil {
+		return doc, err
+	}
+	root := rec.Nodes[0]
+	dataset := provId("dataset", root.Did)
+	activity := provId("processing", root.Did)
+	agent := provId("agent", root.CreateBy)
+	if root.CreateBy == "" {
+		agent = provId("agent", "unknown")
+	}
+
+	doc.addNode(ProvNode{
+		Id: dataset, Kind: "entity", Type: "dbs:Dataset", Label: root.Did, Time: root.CreateAt})
+	doc.addNode(ProvNode{
+		Id: activity, Kind: "activity", Type: "dbs:Processing", Label: root.Processing})
+	doc.addNode(ProvNode{
+		Id: agent, Kind: "agent", Type: provAgentType(root.CreateBy), Label: root.CreateBy})
+	doc.addRelation("wasGeneratedBy", dataset, activity)
+	doc.addRelation("wasAssociatedWith", activity, agent)
+	doc.addRelation("wasAttributedTo", dataset, agent)
+
+	// scripts are activities which inform dataset processing
+	for _, name := range root.Scripts {
+		script := provId("script", name)
+		doc.addNode(ProvNode{
+			Id: script, Kind: "activity", Type: "dbs:Script", Label: name})
+		doc.addRelation("wasInformedBy", activity, script)
+	}
+
+	// input files are used by processing and output files are generated by it
+	for _, f := range root.Files {
+		file := provId("file", f.Name)
+		doc.addNode(ProvNode{
+			Id: file, Kind: "entity", Type: "dbs:File", Label: f.Name,
+			Attrs: map[string]string{"dbs:file_type": f.FileType}})
+		if f.FileType == "input" {
+			doc.addRelation("used", activity, file)
+		} else {
+			doc.addRelation("wasGeneratedBy", file, activity)
+		}
+	}
+
+	// parent datasets
+	for _, node := range rec.Nodes[1:] {
+		parent := provId("dataset", node.Did)
+		doc.addNode(ProvNode{
+			Id: parent, Kind: "entity", Type: "dbs:Dataset", Label: node.Did, Time: node.CreateAt})
+		doc.addRelation("used", activity, parent)
+		doc.addRelation("wasDerivedFrom", dataset, parent)
+	}
+	return doc, nil
+}
+
+// helper function to get PROV type of agent, records created without user
+// identity get "Server" creator by default and are attributed to DBS itself
+func provAgentType(createBy string) string {
+	if createBy == "Server" {
+		return "prov:SoftwareAgent"
+	}
+	return "prov:Person"
+}
+
+// helper function to construct DBS identifier of given kind and name
+func provId(kind, name string) string {
+	var parts []string
+	for _, p := range strings.Split(strings.Trim(name, "/"), "/") {
+		parts = append(parts, url.PathEscape(p))
+	}
+	return fmt.Sprintf("%s/%s", kind, strings.Join(parts, "/"))
+}
+
+// helper function to format unix time as xsd:dateTime
+func provTime(tstamp int64) string {
+	return time.Unix(tstamp, 0).UTC().Format(time.RFC3339)
+}
+
+// helper function to add unique node to the document
+func (d *ProvDocument) addNode(node ProvNode) {
+	if d.ids[node.Id] {
+		return
+	}
+	d.ids[node.Id] = true
+	d.Nodes = append(d.Nodes, node)
+}
+
+// helper function to add relation to the document
+func (d *ProvDocument) addRelation(kind, subject, object string) {
+	d.Relations = append(d.Relations, ProvRelation{Kind: kind, Subject: subject, Object: object})
+}
+
+// ProvJson represents document in PROV-JSON format
+func (d *ProvDocument) ProvJson() map[string]any {
+	out := make(map[string]any)
+	out["prefix"] = map[string]string{"dbs": ProvNamespace}
+	for _, node := range d.Nodes {
+		attrs := map[string]any{"prov:type": node.Type}
+		if node.Label != "" {
+			attrs["prov:label"] = node.Label
+		}
+		for k, v := range node.Attrs {
+			attrs[k] = v
+		}
+		nodes, ok := out[node.Kind].(map[string]any)
+		if !ok {
+			nodes = make(map[string]any)
+			out[node.Kind] = nodes
+		}
+		nodes["dbs:"+node.Id] = attrs
+	}
+	for idx, rel := range d.Relations {
+		keys := provJsonRelations[rel.Kind]
+		attrs := map[string]any{keys[0]: "dbs:" + rel.Subject, keys[1]: "dbs:" + rel.Object}
+		if rel.Kind == "wasGeneratedBy" {
+			if node := d.node(rel.Subject); node.Time > 0 {
+				attrs["prov:time"] = provTime(node.Time)
+			}
+		}
+		rels, ok := out[rel.Kind].(map[string]any)
+		if !ok {
+			rels = make(map[string]any)
+			out[rel.Kind] = rels
+		}
+		rels[fmt.Sprintf("_:%s%d", rel.Kind, idx+1)] = attrs
+	}
+	return out
+}
+
+// JsonLD represents document in PROV-O JSON-LD format
+func (d *ProvDocument) JsonLD() map[string]any {
+	var graph []map[string]any
+	for _, node := range d.Nodes {
+		rec := map[string]any{
+			"@id":   "dbs:" + node.Id,
+			"@type": []string{provClass(node.Kind), node.Type},
+		}
+		if node.Label != "" {
+			rec["rdfs:label"] = node.Label
+		}
+		if node.Kind == "entity" && node.Time > 0 {
+			rec["prov:generatedAtTime"] = map[string]string{
+				"@value": provTime(node.Time), "@type": "xsd:dateTime"}
+		}
+		for k, v := range node.Attrs {
+			rec[k] = v
+		}
+		for _, rel := range d.Relations {
+			if rel.Subject != node.Id {
+				continue
+			}
+			key := "prov:" + rel.Kind
+			ref := map[string]string{"@id": "dbs:" + rel.Object}
+			if refs, ok := rec[key].([]map[string]string); ok {
+				rec[key] = append(refs, ref)
+			} else {
+				rec[key] = []map[string]string{ref}
+			}
+		}
+		graph = append(graph, rec)
+	}
+	context := map[string]string{
+		"prov": provNS,
+		"rdfs": rdfsNS,
+		"xsd":  xsdNS,
+		"dbs":  ProvNamespace,
+	}
+	return map[string]any{"@context": context, "@graph": graph}
+}
+
+// Turtle represents document in PROV-O Turtle format
+func (d *ProvDocument) Turtle() string {
+	literal := func(s string) string {
+		s = strings.Replace(s, "\\", "\\\\", -1)
+		return fmt.Sprintf("\"%s\"", strings.Replace(s, "\"", "\\\"", -1))
+	}
+	var lines []string
+	lines = append(lines,
+		fmt.Sprintf("@prefix prov: <%s> .", provNS),
+		fmt.Sprintf("@prefix rdfs: <%s> .", rdfsNS),
+		fmt.Sprintf("@prefix xsd: <%s> .", xsdNS),
+		fmt.Sprintf("@prefix dbs: <%s> .", ProvNamespace),
+		"")
+	for _, node := range d.Nodes {
+		var props []string
+		props = append(props, fmt.Sprintf("a %s, %s", provClass(node.Kind), node.Type))
+		if node.Label != "" {
+			props = append(props, fmt.Sprintf("rdfs:label %s", literal(node.Label)))
+		}
+		if node.Kind == "entity" && node.Time > 0 {
+			props = append(props,
+				fmt.Sprintf("prov:generatedAtTime \"%s\"^^xsd:dateTime", provTime(node.Time)))
+		}
+		var keys []string
+		for k := range node.Attrs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			props = append(props, fmt.Sprintf("%s %s", k, literal(node.Attrs[k])))
+		}
+		for _, rel := range d.Relations {
+			if rel.Subject == node.Id {
+				props = append(props, fmt.Sprintf("prov:%s <%s%s>", rel.Kind, ProvNamespace, rel.Object))
+			}
+		}
+		lines = append(lines, fmt.Sprintf("<%s%s>\n    %s .", ProvNamespace, node.Id, strings.Join(props, " ;\n    ")))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// helper function to find node with given id
+func (d *ProvDocument) node(id string) ProvNode {
+	for _, node := range d.Nodes {
+		if node.Id == id {
+			return node
+		}
+	}
+	return ProvNode{}
+}
+
+// helper function to get PROV-O class of given node kind
+func provClass(kind string) string {
+	switch kind {
+	case "activity":
+		return "prov:Activity"
+	case "agent":
+		return "prov:Agent"
+	}
+	return "prov:Entity"
+}
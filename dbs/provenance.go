@@ -19,6 +19,7 @@ type ProvenanceRecord struct {
 	Site         string              `json:"site"`
 	Processing   string              `json:"processing"`
 	ParentDid    string              `json:"parent_did"`
+	ParentDids   []string            `json:"parent_dids"`
 	InputFiles   []FileRecord        `json:"input_files"`
 	OutputFiles  []FileRecord        `json:"output_files"`
 	Environments []EnvironmentRecord `json:"environments"`
@@ -34,6 +35,9 @@ func (p *ProvenanceRecord) IsEmpty() bool {
 	if strings.TrimSpace(p.Site) != "" || strings.TrimSpace(p.Processing) != "" || strings.TrimSpace(p.ParentDid) != "" {
 		return false
 	}
+	if len(p.ParentDids) > 0 {
+		return false
+	}
 	if len(p.InputFiles) > 0 {
 		return false
 	}
@@ -64,8 +68,19 @@ func (p *ProvenanceRecord) IsEmpty() bool {
 	return true
 }
 
-//gocyclo:ignore
+// GetParentDID returns first parent did of given dataset did
 func (a *API) GetParentDID(did string) (string, error) {
+	parents, err := a.GetParentDIDs(did)
+	if err != nil {
+		return "", err
+	}
+	return parents[0], nil
+}
+
+// GetParentDIDs returns all parent dids of given dataset did
+//
+//gocyclo:ignore
+func (a *API) GetParentDIDs(did string) ([]string, error) {
 	var args []interface{}
 	var conds []string
 	tmpl := make(map[string]any)
@@ -74,46 +89,48 @@ func (a *API) GetParentDID(did string) (string, error) {
 	//stm, err := LoadTemplateSQL("select_parent_did", tmpl)
 	stm, err := LoadTemplateSQL("select_parent", tmpl)
 	if err != nil {
-		return "", Error(err, LoadErrorCode, "fail to load select_parent_did sql template", "dbs.provenance.GetParentDID")
+		return nil, Error(err, LoadErrorCode, "fail to load select_parent sql template", "dbs.provenance.GetParentDIDs")
 	}
 	if did != "" {
 		args = append(args, did)
-		conds = append(conds, "d.did = ?")
+		conds = append(conds, fmt.Sprintf("d.did = %s", placeholder("did")))
 	}
 	stm = WhereClause(stm, conds)
 
-	tx, err := DB.Begin()
-	if err != nil {
-		return "", Error(err, TransactionErrorCode, "fail to get DB transaction", "dbs.provenance.GetProvenance")
-	}
-	defer tx.Rollback()
-	rows, err := tx.Query(stm, args...)
+	rows, err := DB.Query(stm, args...)
 
 	if err != nil {
 		msg := "unable to query database"
-		return "", Error(err, QueryErrorCode, msg, "dbs.API.GetParentDID")
+		return nil, Error(err, QueryErrorCode, msg, "dbs.API.GetParentDIDs")
 	}
 	defer rows.Close()
 
 	log.Println("QUERY:\n", stm, args)
 
-	var parentDID sql.NullString
-	var cat, cby, mat, mby any
+	var parents []string
 	for rows.Next() {
+		var parentDID sql.NullString
+		var cat, cby, mat, mby any
 		err := rows.Scan(&did, &parentDID, &cat, &cby, &mat, &mby)
 		if err != nil {
 			msg := "unable to scan database rows"
-			return "", Error(err, RowsScanErrorCode, msg, "dbs.API.GetParentDID")
+			return nil, Error(err, RowsScanErrorCode, msg, "dbs.API.GetParentDIDs")
+		}
+		if parentDID.Valid {
+			parents = append(parents, parentDID.String)
 		}
-		break
 	}
-	if parentDID.Valid {
-		return parentDID.String, nil
+	if len(parents) > 0 {
+		return parents, nil
 	}
 	msg := fmt.Sprintf("parent for did %s is not found", did)
-	return "", errors.New(msg)
+	return nil, errors.New(msg)
 }
 
+// GetProvenance provides provenance records of datasets matching given did(s).
+// The did parameter may be provided multiple times and may contain wildcards,
+// the API yields one provenance record per matching dataset.
+//
 //gocyclo:ignore
 func (a *API) GetProvenance() error {
 	if Verbose > 1 {
@@ -124,7 +141,8 @@ func (a *API) GetProvenance() error {
 	tmpl := make(map[string]any)
 	tmpl["Owner"] = DBOWNER
 
-	allowed := []string{"did"}
+	allowed := []string{"did", "format"}
+	allowed = append(allowed, PaginationKeys...)
 	for k, _ := range a.Params {
 		if !utils.InList(k, allowed) {
 			msg := fmt.Sprintf("invalid parameter %s", k)
@@ -133,33 +151,98 @@ func (a *API) GetProvenance() error {
 
 	}
 
-	var dataset_did string
-	if val, ok := a.Params["did"]; ok {
+	var dids []string
+	for _, val := range getValues(a.Params, "did") {
+		val = strings.Trim(strings.Replace(strings.Replace(val, "[", "", -1), "]", "", -1), " ")
 		if val != "" {
-			conds, args = AddParam("did", "d.did", a.Params, conds, args)
-			dataset_did = fmt.Sprintf("%v", val)
-			dataset_did = strings.Replace(dataset_did, "[", "", -1)
-			dataset_did = strings.Replace(dataset_did, "]", "", -1)
+			dids = append(dids, val)
 		}
-	} else {
+	}
+	if len(dids) == 0 {
 		msg := fmt.Sprintf("/provenance API requires did input, got %+v\n", a.Params)
 		return errors.New(msg)
 	}
 
+	// W3C PROV representation of provenance record
+	if _, ok := a.Params["format"]; ok {
+		format, err := getSingleValue(a.Params, "format")
+		if err != nil {
+			return Error(err, ParametersErrorCode, "", "dbs.provenance.GetProvenance")
+		}
+		if format != "json" {
+			if len(dids) != 1 || strings.Contains(dids[0], "*") {
+				msg := "W3C PROV formats require single did"
+				return Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.provenance.GetProvenance")
+			}
+			return a.GetProv(dids[0], format)
+		}
+	}
+
+	// build did condition, multiple dids are OR'ed together
+	var didConds []string
+	for _, did := range dids {
+		op, val := OperatorValue(did)
+		didConds = append(didConds, fmt.Sprintf("d.did %s %s", op, placeholder("did")))
+		args = append(args, val)
+	}
+	didCond := fmt.Sprintf("(%s)", strings.Join(didConds, " OR "))
+
+	// count matching datasets
+	count, err := getBoolParam(a.Params, "count")
+	if err != nil {
+		return Error(err, ParametersErrorCode, "", "dbs.provenance.GetProvenance")
+	}
+	if count {
+		stm := fmt.Sprintf("SELECT DISTINCT d.did FROM datasets d WHERE %s", didCond)
+		return a.writeCount(stm, args...)
+	}
+
+	// paginate over matching datasets rather than provenance rows
+	page, err := a.getPagination()
+	if err != nil {
+		return Error(err, ParametersErrorCode, "", "dbs.provenance.GetProvenance")
+	}
+	if page.Limit > 0 || len(page.Sort) > 0 {
+		dids, err := a.provenanceDids(page, didCond, args...)
+		if err != nil {
+			return Error(err, QueryErrorCode, "", "dbs.provenance.GetProvenance")
+		}
+		if len(dids) == 0 {
+			if a.Separator != "" {
+				a.Writer.Write([]byte("[]\n"))
+			}
+			return nil
+		}
+		var pholders []string
+		args = []interface{}{}
+		for _, did := range dids {
+			pholders = append(pholders, placeholder("did"))
+			args = append(args, did)
+		}
+		didCond = fmt.Sprintf("d.did IN (%s)", strings.Join(pholders, ","))
+	}
+	conds = append(conds, didCond)
+
 	// get SQL statement from static area
 	stm, err := LoadTemplateSQL("select_provenance", tmpl)
 	if err != nil {
 		return Error(err, LoadErrorCode, "fail to load select_provenance sql template", "dbs.datasets.Datasets")
 	}
 	stm = WhereClause(stm, conds)
-	stm = fmt.Sprintf("%s ORDER BY d.dataset_id, e.environment_id, pk.package_id", stm)
+	// rows of a dataset should be adjacent to build its provenance record
+	order := "d.dataset_id"
+	if len(page.Sort) > 0 {
+		order = "d." + page.Sort[0]
+	}
+	stm = fmt.Sprintf("%s ORDER BY %s, e.environment_id, pk.package_id", stm, order)
 
-	tx, err := DB.Begin()
+	// parent dids of all matching datasets are fetched before provenance rows
+	parents, err := provenanceParents(didCond, args...)
 	if err != nil {
-		return Error(err, TransactionErrorCode, "fail to get DB transaction", "dbs.provenance.GetProvenance")
+		return Error(err, QueryErrorCode, "", "dbs.API.GetProvenance")
 	}
-	defer tx.Rollback()
-	rows, err := tx.Query(stm, args...)
+
+	rows, err := DB.Query(stm, args...)
 
 	if err != nil {
 		msg := "unable to query database"
@@ -169,149 +252,257 @@ func (a *API) GetProvenance() error {
 
 	log.Println("QUERY:\n", stm, args)
 
-	// Process results
-	var provenance DatasetRecord
-
-	// keep map of unique packages
-	envMap := make(map[int]*EnvironmentRecord)  // Store environments by environment_id
-	pkgMap := make(map[int]map[string]struct{}) // Track unique packages per environment
-	scriptMap := make(map[int64]*ScriptRecord)  // Store scripts by script_id
-
-	// find parent did
-	parentDID, err := a.GetParentDID(dataset_did)
-	if err != nil {
-		log.Println("WARNING:", err)
-	}
-
-	// main query
+	// rows are ordered by dataset, therefore we build provenance record of
+	// a dataset and write it out as soon as rows of next dataset appear
+	var builder *provenanceBuilder
+	nrec := 0
 	for rows.Next() {
-		var did, processing, osName, osKernel, osVersion string
-		var didSql, processingSql, osNameSql, osKernelSql, osVersionSql sql.NullString
-		var bucketName, bucketUUID, bucketMetaData sql.NullString
-		var site, scriptName, scriptOptions sql.NullString
-		var parentEnvName, parentScript, packageName, packageVersion sql.NullString
-		var envName, envVersion, envDetails, envOSName, config sql.NullString
-		var scriptID, scriptOrderIdx sql.NullInt64
-		var envIDSql sql.NullInt32
-		var envID int
-
-		// Scan row into variables
-		err := rows.Scan(&didSql, &processingSql, &osNameSql, &osKernelSql, &osVersionSql,
-			&envIDSql, &envName, &envVersion, &envDetails, &parentEnvName, &envOSName,
-			&packageName, &packageVersion,
-			&scriptID, &scriptName, &scriptOrderIdx, &scriptOptions, &parentScript,
-			&site, &config, &bucketName, &bucketUUID, &bucketMetaData,
-		)
-		if err != nil {
+		var row provenanceRow
+		if err := row.scan(rows); err != nil {
 			log.Println("ERROR: unable to scan rows", err)
 			msg := "unable to scan database rows"
 			return Error(err, ProvenanceErrorCode, msg, "dbs.API.GetProvenance")
 		}
-		if didSql.Valid {
-			did = didSql.String
-		}
-		if envIDSql.Valid {
-			envID = int(envIDSql.Int32)
-		}
-		if processingSql.Valid {
-			processing = processingSql.String
-		}
-		if osNameSql.Valid {
-			osName = osNameSql.String
-		}
-		if osKernelSql.Valid {
-			osKernel = osKernelSql.String
-		}
-		if osVersionSql.Valid {
-			osVersion = osVersionSql.String
-		}
-		// Initialize provenance record if first row
-		if provenance.Did == "" {
-			provenance = DatasetRecord{
-				Did:        did,
-				Parent:     parentDID,
-				Processing: processing,
-				OsInfo: OsInfoRecord{
-					Name:    osName,
-					Kernel:  osKernel,
-					Version: osVersion,
-				},
-				Environments: []EnvironmentRecord{},
-				Site:         site.String,
-				Scripts:      []ScriptRecord{},
-				Buckets:      []BucketRecord{},
+		if builder != nil && builder.record.Did != row.did.String {
+			if err := a.writeProvenance(builder.build(), nrec); err != nil {
+				return err
 			}
+			nrec++
+			builder = nil
 		}
-
-		// config
-		if config.Valid {
-			provenance.Config = config.String
+		if builder == nil {
+			builder = newProvenanceBuilder(row, parents[row.datasetID.Int64])
 		}
-
-		// Collect buckets
-		b := BucketRecord{}
-		if bucketName.Valid {
-			b.Name = bucketName.String
+		builder.add(row)
+	}
+	if err = rows.Err(); err != nil {
+		return Error(err, RowsScanErrorCode, "", "dbs.API.GetProvenance")
+	}
+	if builder != nil {
+		if err := a.writeProvenance(builder.build(), nrec); err != nil {
+			return err
 		}
-		if bucketUUID.Valid {
-			b.UUID = bucketUUID.String
+		nrec++
+	}
+	if a.Separator != "" {
+		if nrec == 0 {
+			a.Writer.Write([]byte("["))
 		}
-		if bucketMetaData.Valid {
-			b.MetaData = bucketMetaData.String
+		a.Writer.Write([]byte("]\n"))
+	}
+	return nil
+}
+
+// helper function to get parent dids of datasets matching given condition,
+// the parent dids are keyed by dataset id and ordered by parent id
+func provenanceParents(cond string, args ...interface{}) (map[int64][]string, error) {
+	stm := WhereClause(getSQL("select_dataset_parents"), []string{cond})
+	stm = fmt.Sprintf("%s ORDER BY p.dataset_id, p.parent_id", stm)
+	rows, err := DB.Query(stm, args...)
+	if err != nil {
+		return nil, Error(err, QueryErrorCode, "", "dbs.provenance.provenanceParents")
+	}
+	defer rows.Close()
+	parents := make(map[int64][]string)
+	for rows.Next() {
+		var datasetId, parentId int64
+		var parentDid string
+		if err := rows.Scan(&datasetId, &parentId, &parentDid); err != nil {
+			return nil, Error(err, RowsScanErrorCode, "", "dbs.provenance.provenanceParents")
 		}
-		provenance.Buckets = append(provenance.Buckets, b)
+		parents[datasetId] = append(parents[datasetId], parentDid)
+	}
+	return parents, rows.Err()
+}
 
-		if envOSName.Valid {
-			osName = envOSName.String
+// helper function to get page of dataset dids matching given condition
+func (a *API) provenanceDids(page Pagination, cond string, args ...interface{}) ([]string, error) {
+	stm := fmt.Sprintf("SELECT DISTINCT d.did FROM datasets d WHERE %s", cond)
+	if err := a.setPageHeaders(page, stm, args...); err != nil {
+		return nil, err
+	}
+	rows, err := DB.Query(page.Apply(stm), args...)
+	if err != nil {
+		return nil, Error(err, QueryErrorCode, "", "dbs.provenance.provenanceDids")
+	}
+	defer rows.Close()
+	var dids []string
+	for rows.Next() {
+		var did string
+		if err := rows.Scan(&did); err != nil {
+			return nil, Error(err, RowsScanErrorCode, "", "dbs.provenance.provenanceDids")
 		}
-		// Handle scripts
-		var sid int64
-		if scriptID.Valid {
-			sid = scriptID.Int64
-			if _, exists := scriptMap[sid]; !exists {
-				scriptMap[sid] = &ScriptRecord{
-					Name:     scriptName.String,
-					OrderIdx: scriptOrderIdx.Int64,
-					Options:  scriptOptions.String,
-					Parent:   parentScript.String,
-				}
-			}
+		dids = append(dids, did)
+	}
+	return dids, rows.Err()
+}
+
+// helper function to write provenance record as JSON list element or NDJSON record
+func (a *API) writeProvenance(rec DatasetRecord, idx int) error {
+	var data []byte
+	var err error
+	if a.Separator == "" {
+		data, err = json.Marshal(rec)
+		data = append(data, '\n')
+	} else {
+		data, err = json.MarshalIndent(rec, "  ", "  ")
+		if idx == 0 {
+			data = append([]byte("[\n  "), data...)
+		} else {
+			data = append([]byte(",\n  "), data...)
 		}
+	}
+	if err != nil {
+		msg := "unable to marhsl output records"
+		return Error(err, ProvenanceErrorCode, msg, "dbs.API.GetProvenance")
+	}
+	a.Writer.Write(data)
+	return nil
+}
 
-		// Handle environments
-		if _, exists := envMap[envID]; !exists {
-			envMap[envID] = &EnvironmentRecord{
-				Name:     envName.String,
-				Version:  envVersion.String,
-				Details:  envDetails.String,
-				Parent:   parentEnvName.String,
-				OSName:   osName,
-				Packages: []PackageRecord{},
-			}
-			pkgMap[envID] = make(map[string]struct{}) // Track unique packages
-		}
-
-		// Check if the package is already in the set before adding
-		if packageName.Valid && packageVersion.Valid {
-			pkgKey := packageName.String + "|" + packageVersion.String
-			if _, exists := pkgMap[envID][pkgKey]; !exists {
-				envMap[envID].Packages = append(envMap[envID].Packages, PackageRecord{
-					Name:    packageName.String,
-					Version: packageVersion.String,
-				})
-				pkgMap[envID][pkgKey] = struct{}{}
+// provenanceRow represents single row of select_provenance query
+type provenanceRow struct {
+	did, processing, osName, osKernel, osVersion   sql.NullString
+	bucketName, bucketUUID, bucketMetaData         sql.NullString
+	site, scriptName, scriptOptions                sql.NullString
+	parentEnvName, parentScript                    sql.NullString
+	packageName, packageVersion                    sql.NullString
+	envName, envVersion, envDetails, envOSName     sql.NullString
+	config                                         sql.NullString
+	datasetID, packageID, scriptID, scriptOrderIdx sql.NullInt64
+	envID                                          sql.NullInt32
+}
+
+// helper function to scan provenance row from select_provenance query rows
+func (row *provenanceRow) scan(rows *sql.Rows) error {
+	return rows.Scan(&row.did, &row.datasetID, &row.processing, &row.osName, &row.osKernel, &row.osVersion,
+		&row.envID, &row.envName, &row.envVersion, &row.envDetails, &row.parentEnvName, &row.envOSName,
+		&row.packageID, &row.packageName, &row.packageVersion,
+		&row.scriptID, &row.scriptName, &row.scriptOrderIdx, &row.scriptOptions, &row.parentScript,
+		&row.site, &row.config, &row.bucketName, &row.bucketUUID, &row.bucketMetaData,
+	)
+}
+
+// provenanceBuilder builds provenance record of a dataset from its rows
+type provenanceBuilder struct {
+	record    DatasetRecord
+	envIds    []int                       // keep order of environments
+	envMap    map[int]*EnvironmentRecord  // Store environments by environment_id
+	pkgMap    map[int]map[string]struct{} // Track unique packages per environment
+	scriptIds []int64                     // keep order of scripts
+	scriptMap map[int64]*ScriptRecord     // Store scripts by script_id
+}
+
+// helper function to initialize provenance builder from first row of a dataset
+// and list of its parent dids
+func newProvenanceBuilder(row provenanceRow, parentDIDs []string) *provenanceBuilder {
+	var parentDID string
+	if len(parentDIDs) > 0 {
+		parentDID = parentDIDs[0]
+	}
+	return &provenanceBuilder{
+		record: DatasetRecord{
+			Did:        row.did.String,
+			Parent:     parentDID,
+			Parents:    parentDIDs,
+			Processing: row.processing.String,
+			OsInfo: OsInfoRecord{
+				Name:    row.osName.String,
+				Kernel:  row.osKernel.String,
+				Version: row.osVersion.String,
+			},
+			Environments: []EnvironmentRecord{},
+			Site:         row.site.String,
+			Scripts:      []ScriptRecord{},
+			Buckets:      []BucketRecord{},
+		},
+		envMap:    make(map[int]*EnvironmentRecord),
+		pkgMap:    make(map[int]map[string]struct{}),
+		scriptMap: make(map[int64]*ScriptRecord),
+	}
+}
+
+// helper function to add row information to provenance record
+func (p *provenanceBuilder) add(row provenanceRow) {
+	var envID int
+	if row.envID.Valid {
+		envID = int(row.envID.Int32)
+	}
+	osName := row.osName.String
+
+	// config
+	if row.config.Valid {
+		p.record.Config = configValue(row.config.String)
+	}
+
+	// Collect buckets
+	b := BucketRecord{}
+	if row.bucketName.Valid {
+		b.Name = row.bucketName.String
+	}
+	if row.bucketUUID.Valid {
+		b.UUID = row.bucketUUID.String
+	}
+	if row.bucketMetaData.Valid {
+		b.MetaData = row.bucketMetaData.String
+	}
+	p.record.Buckets = append(p.record.Buckets, b)
+
+	if row.envOSName.Valid {
+		osName = row.envOSName.String
+	}
+	// Handle scripts
+	if row.scriptID.Valid {
+		sid := row.scriptID.Int64
+		if _, exists := p.scriptMap[sid]; !exists {
+			p.scriptIds = append(p.scriptIds, sid)
+			p.scriptMap[sid] = &ScriptRecord{
+				Name:     row.scriptName.String,
+				OrderIdx: row.scriptOrderIdx.Int64,
+				Options:  row.scriptOptions.String,
+				Parent:   row.parentScript.String,
 			}
 		}
+	}
+
+	// Handle environments
+	if _, exists := p.envMap[envID]; !exists {
+		p.envIds = append(p.envIds, envID)
+		p.envMap[envID] = &EnvironmentRecord{
+			Name:     row.envName.String,
+			Version:  row.envVersion.String,
+			Details:  row.envDetails.String,
+			Parent:   row.parentEnvName.String,
+			OSName:   osName,
+			Packages: []PackageRecord{},
+		}
+		p.pkgMap[envID] = make(map[string]struct{}) // Track unique packages
+	}
 
+	// Check if the package is already in the set before adding
+	if row.packageName.Valid && row.packageVersion.Valid {
+		pkgKey := row.packageName.String + "|" + row.packageVersion.String
+		if _, exists := p.pkgMap[envID][pkgKey]; !exists {
+			p.envMap[envID].Packages = append(p.envMap[envID].Packages, PackageRecord{
+				Name:    row.packageName.String,
+				Version: row.packageVersion.String,
+			})
+			p.pkgMap[envID][pkgKey] = struct{}{}
+		}
 	}
+}
 
+// helper function to build final provenance record
+func (p *provenanceBuilder) build() DatasetRecord {
+	provenance := p.record
 	// Convert environments map to list of environments in provenance record
-	for _, env := range envMap {
-		provenance.Environments = append(provenance.Environments, *env)
+	for _, envID := range p.envIds {
+		provenance.Environments = append(provenance.Environments, *p.envMap[envID])
 	}
 	// Convert scripts map to list of scripts in provenance record
 	smap := make(map[string]struct{})
-	for _, script := range scriptMap {
+	for _, sid := range p.scriptIds {
+		script := p.scriptMap[sid]
 		if _, exists := smap[script.Name]; !exists {
 			provenance.Scripts = append(provenance.Scripts, *script)
 			smap[script.Name] = struct{}{}
@@ -320,17 +511,7 @@ func (a *API) GetProvenance() error {
 
 	// get rid of duplicates
 	provenance.Buckets = UniqueBucketRecords(provenance.Buckets)
-
-	// Convert to JSON
-	var out []DatasetRecord
-	out = append(out, provenance)
-	jsonOutput, err := json.MarshalIndent(out, "", "  ")
-	if err == nil {
-		a.Writer.Write(jsonOutput)
-		return nil
-	}
-	msg := "unable to marhsl output records"
-	return Error(err, ProvenanceErrorCode, msg, "dbs.API.GetProvenance")
+	return provenance
 }
 
 // UniqueBucketRecords removes duplicates from a slice and returns a new slice with unique elements.
@@ -386,6 +567,7 @@ func (a *API) InsertProvenance() error {
 	// parameters for provenance record
 	var inputFiles, outputFiles []FileRecord
 	var user, did, parentDid, application, site string
+	var parentDids []string
 	var config any
 
 	// extract all possible values from input user record
@@ -405,6 +587,17 @@ func (a *API) InsertProvenance() error {
 		tstamp := time.Now().Format("20060102_150405")
 		did = fmt.Sprintf("%s/%s:%s", val, user, tstamp)
 	}
+	if val, ok := userRecord["parent_dids"]; ok {
+		if vals, ok := val.([]any); ok {
+			for _, v := range vals {
+				parentDids = append(parentDids, fmt.Sprintf("%v", v))
+			}
+		}
+		if did == "" && len(parentDids) > 0 {
+			tstamp := time.Now().Format("20060102_150405")
+			did = fmt.Sprintf("%s/%s:%s", parentDids[0], user, tstamp)
+		}
+	}
 	if val, ok := userRecord["did"]; ok {
 		did = val.(string)
 	}
@@ -438,6 +631,7 @@ func (a *API) InsertProvenance() error {
 		Site:         site,
 		Processing:   application,
 		Parent:       parentDid,
+		Parents:      parentDids,
 		InputFiles:   inputFiles,
 		OutputFiles:  outputFiles,
 		Environments: environments,
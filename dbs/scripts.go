@@ -45,11 +45,15 @@ func (a *API) GetScript() error {
 	if val, ok := a.Params["did"]; ok && val != "" {
 		conds, args = AddParam("did", "d.did", a.Params, conds, args)
 	}
+	conds, args, err = addFullTextParam("scripts", "s", a.Params, conds, args)
+	if err != nil {
+		return Error(err, ParametersErrorCode, "", "dbs.scripts.Scripts")
+	}
 
 	stm = WhereClause(stm, conds)
 
 	// use generic query API to fetch the results from DB
-	err = executeAll(a.Writer, a.Separator, stm, args...)
+	err = a.executePage(stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "", "dbs.scripts.Scripts")
 	}
@@ -158,7 +162,8 @@ func (r *Scripts) Insert(tx *sql.Tx) (int64, error) {
 	} else if Verbose > 1 {
 		log.Printf("Insert Scripts\n%s\n%+v", stm, r)
 	}
-	_, err = tx.Exec(
+	r.SCRIPT_ID, err = execInsert(
+		tx,
 		stm,
 		r.SCRIPT_ID,
 		r.NAME,
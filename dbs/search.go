@@ -0,0 +1,413 @@
+package dbs
+
+// DBS query language module
+//
+// Search API accepts single query string, e.g.
+// did:/beamline=3a/* AND package:numpy AND (site:Cornell OR NOT osname:linux*)
+// which consists of key:value terms combined with AND, OR and NOT operators
+// and parentheses, adjacent terms are combined with AND. Values may contain
+// * wildcards, be quoted to include spaces, or define inclusive ranges
+// [min TO max] where * denotes open end. Supported keys, their columns and
+// joins of select_dataset.sql are defined in static/ql_keys.json file.
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/CHESSComputing/golib/utils"
+)
+
+// QLKey represents key of DBS query language
+type QLKey struct {
+	Key         string `json:"key"`
+	Column      string `json:"column"`         // SQL column of the key
+	Join        string `json:"join,omitempty"` // join flag of select_dataset.sql template
+	Type        string `json:"type"`           // type of key values: string or int
+	Description string `json:"description"`
+}
+
+// QLKeys holds keys of DBS query language
+var QLKeys []QLKey
+
+// LoadQLKeys loads keys of DBS query language from ql_keys.json file of
+// static area
+func LoadQLKeys() ([]QLKey, error) {
+	var keys []QLKey
+	data, err := ReadStaticFile("ql_keys.json")
+	if err != nil {
+		return keys, Error(err, ReaderErrorCode, "unable to read ql_keys.json", "dbs.LoadQLKeys")
+	}
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return keys, Error(err, UnmarshalErrorCode, "unable to parse ql_keys.json", "dbs.LoadQLKeys")
+	}
+	for _, k := range keys {
+		if k.Key == "" || k.Column == "" || (k.Type != "string" && k.Type != "int") {
+			msg := fmt.Sprintf("invalid query language key %+v", k)
+			return keys, Error(InvalidParamErr, ParseErrorCode, msg, "dbs.LoadQLKeys")
+		}
+	}
+	return keys, nil
+}
+
+// Search API provides datasets matching given query
+func (a *API) Search() error {
+	allowed := append([]string{"query"}, PaginationKeys...)
+	for k := range a.Params {
+		if !utils.InList(k, allowed) {
+			msg := fmt.Sprintf("invalid parameter %s", k)
+			return Error(InvalidParamErr, ParametersErrorCode, msg, "dbs.search.Search")
+		}
+	}
+	query, err := getSingleValue(a.Params, "query")
+	if err != nil || strings.TrimSpace(query) == "" {
+		return Error(InvalidParamErr, ParametersErrorCode, "no query is provided", "dbs.search.Search")
+	}
+	cond, args, err := ParseQuery(query)
+	if err != nil {
+		return err
+	}
+
+	tmpl := make(map[string]any)
+	tmpl["Owner"] = DBOWNER
+	stm, err := LoadTemplateSQL("select_dataset", tmpl)
+	if err != nil {
+		return Error(err, LoadErrorCode, "", "dbs.search.Search")
+	}
+	stm = WhereClause(stm, []string{cond})
+
+	// use generic query API to fetch the results from DB
+	err = a.executePage(stm, args...)
+	if err != nil {
+		return Error(err, QueryErrorCode, "", "dbs.search.Search")
+	}
+	return nil
+}
+
+// ParseQuery translates query of DBS query language into SQL condition of
+// select_dataset.sql statement and its bind values. Invalid datasets are
+// excluded unless query contains status key.
+func ParseQuery(query string) (string, []interface{}, error) {
+	if QLKeys == nil {
+		keys, err := LoadQLKeys()
+		if err != nil {
+			return "", nil, err
+		}
+		QLKeys = keys
+	}
+	tokens, err := qlTokenize(query)
+	if err != nil {
+		return "", nil, err
+	}
+	p := &qlParser{tokens: tokens, keys: make(map[string]QLKey)}
+	for _, k := range QLKeys {
+		p.keys[k.Key] = k
+	}
+	cond, err := p.parseOr()
+	if err != nil {
+		return "", nil, err
+	}
+	if tok := p.peek(); tok != nil {
+		msg := fmt.Sprintf("unexpected '%s' at position %d", tok.val, tok.pos)
+		return "", nil, Error(InvalidParamErr, ParseErrorCode, msg, "dbs.ParseQuery")
+	}
+	if !p.status {
+		cond = fmt.Sprintf("(%s) AND d.status <> %s", cond, placeholder("status"))
+		p.args = append(p.args, DatasetInvalid)
+	}
+	return cond, p.args, nil
+}
+
+// kinds of query language tokens
+const (
+	qlTerm = iota
+	qlAnd
+	qlOr
+	qlNot
+	qlOpen
+	qlClose
+)
+
+// qlToken represents token of query language
+type qlToken struct {
+	kind  int
+	val   string // original text of token
+	key   string // key of term
+	value string // value of term
+	pos   int    // position of token in query
+}
+
+// helper function to split query into tokens
+func qlTokenize(query string) ([]qlToken, error) {
+	var tokens []qlToken
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+			continue
+		case c == '(':
+			tokens = append(tokens, qlToken{kind: qlOpen, val: "(", pos: i})
+			i++
+			continue
+		case c == ')':
+			tokens = append(tokens, qlToken{kind: qlClose, val: ")", pos: i})
+			i++
+			continue
+		}
+		start := i
+		for i < len(runes) && runes[i] != ':' && runes[i] != ')' && !unicode.IsSpace(runes[i]) {
+			i++
+		}
+		word := string(runes[start:i])
+		if i == len(runes) || runes[i] != ':' {
+			switch strings.ToUpper(word) {
+			case "AND":
+				tokens = append(tokens, qlToken{kind: qlAnd, val: word, pos: start})
+			case "OR":
+				tokens = append(tokens, qlToken{kind: qlOr, val: word, pos: start})
+			case "NOT":
+				tokens = append(tokens, qlToken{kind: qlNot, val: word, pos: start})
+			default:
+				msg := fmt.Sprintf("invalid term '%s' at position %d, should be key:value", word, start)
+				return nil, Error(InvalidParamErr, ParseErrorCode, msg, "dbs.qlTokenize")
+			}
+			continue
+		}
+
+		// read value of key:value term
+		i++
+		vstart := i
+		var value string
+		switch {
+		case i < len(runes) && runes[i] == '"':
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				i++
+			}
+			if i == len(runes) {
+				msg := fmt.Sprintf("unterminated quote at position %d", vstart)
+				return nil, Error(InvalidParamErr, ParseErrorCode, msg, "dbs.qlTokenize")
+			}
+			value = string(runes[vstart+1 : i])
+			i++
+		case i < len(runes) && runes[i] == '[':
+			for i < len(runes) && runes[i] != ']' {
+				i++
+			}
+			if i == len(runes) {
+				msg := fmt.Sprintf("unterminated range at position %d", vstart)
+				return nil, Error(InvalidParamErr, ParseErrorCode, msg, "dbs.qlTokenize")
+			}
+			i++
+			value = string(runes[vstart:i])
+		default:
+			for i < len(runes) && runes[i] != ')' && !unicode.IsSpace(runes[i]) {
+				i++
+			}
+			value = string(runes[vstart:i])
+		}
+		if value == "" {
+			msg := fmt.Sprintf("no value of key %s at position %d", word, start)
+			return nil, Error(InvalidParamErr, ParseErrorCode, msg, "dbs.qlTokenize")
+		}
+		tokens = append(tokens, qlToken{
+			kind: qlTerm, val: string(runes[start:i]), key: word, value: value, pos: start})
+	}
+	return tokens, nil
+}
+
+// qlParser represents recursive descent parser of query language which
+// builds SQL condition and its bind values
+type qlParser struct {
+	tokens []qlToken
+	idx    int
+	keys   map[string]QLKey
+	args   []interface{}
+	status bool // query contains status key
+}
+
+// helper function to get current token without consuming it
+func (p *qlParser) peek() *qlToken {
+	if p.idx < len(p.tokens) {
+		return &p.tokens[p.idx]
+	}
+	return nil
+}
+
+// helper function to parse OR expression: and (OR and)*
+func (p *qlParser) parseOr() (string, error) {
+	cond, err := p.parseAnd()
+	if err != nil {
+		return "", err
+	}
+	conds := []string{cond}
+	for tok := p.peek(); tok != nil && tok.kind == qlOr; tok = p.peek() {
+		p.idx++
+		cond, err := p.parseAnd()
+		if err != nil {
+			return "", err
+		}
+		conds = append(conds, cond)
+	}
+	if len(conds) == 1 {
+		return conds[0], nil
+	}
+	return "(" + strings.Join(conds, " OR ") + ")", nil
+}
+
+// helper function to parse AND expression: not (AND? not)*
+func (p *qlParser) parseAnd() (string, error) {
+	cond, err := p.parseNot()
+	if err != nil {
+		return "", err
+	}
+	conds := []string{cond}
+	for tok := p.peek(); tok != nil && tok.kind != qlOr && tok.kind != qlClose; tok = p.peek() {
+		if tok.kind == qlAnd {
+			p.idx++
+		}
+		cond, err := p.parseNot()
+		if err != nil {
+			return "", err
+		}
+		conds = append(conds, cond)
+	}
+	if len(conds) == 1 {
+		return conds[0], nil
+	}
+	return "(" + strings.Join(conds, " AND ") + ")", nil
+}
+
+// helper function to parse NOT expression: NOT not | primary
+func (p *qlParser) parseNot() (string, error) {
+	if tok := p.peek(); tok != nil && tok.kind == qlNot {
+		p.idx++
+		cond, err := p.parseNot()
+		if err != nil {
+			return "", err
+		}
+		return "NOT " + cond, nil
+	}
+	return p.parsePrimary()
+}
+
+// helper function to parse primary expression: ( or ) | term
+func (p *qlParser) parsePrimary() (string, error) {
+	tok := p.peek()
+	if tok == nil {
+		return "", Error(InvalidParamErr, ParseErrorCode, "unexpected end of query", "dbs.qlParser.parsePrimary")
+	}
+	p.idx++
+	switch tok.kind {
+	case qlOpen:
+		cond, err := p.parseOr()
+		if err != nil {
+			return "", err
+		}
+		if next := p.peek(); next == nil || next.kind != qlClose {
+			msg := fmt.Sprintf("missing closing parenthesis of position %d", tok.pos)
+			return "", Error(InvalidParamErr, ParseErrorCode, msg, "dbs.qlParser.parsePrimary")
+		}
+		p.idx++
+		return "(" + cond + ")", nil
+	case qlTerm:
+		return p.term(tok)
+	}
+	msg := fmt.Sprintf("unexpected '%s' at position %d", tok.val, tok.pos)
+	return "", Error(InvalidParamErr, ParseErrorCode, msg, "dbs.qlParser.parsePrimary")
+}
+
+// helper function to translate key:value term into SQL condition, terms of
+// keys which require joins are translated into sub-query of dataset ids
+// since datasets may have many environments, packages, files, etc.
+func (p *qlParser) term(tok *qlToken) (string, error) {
+	key, ok := p.keys[tok.key]
+	if !ok {
+		var keys []string
+		for k := range p.keys {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		msg := fmt.Sprintf("invalid key '%s', supported keys: %v", tok.key, keys)
+		return "", Error(InvalidParamErr, ParseErrorCode, msg, "dbs.qlParser.term")
+	}
+	var cond string
+	if strings.HasPrefix(tok.value, "[") {
+		arr := strings.Fields(strings.Trim(tok.value, "[]"))
+		if len(arr) != 3 || strings.ToUpper(arr[1]) != "TO" {
+			msg := fmt.Sprintf("invalid range '%s', should be [min TO max]", tok.value)
+			return "", Error(InvalidParamErr, ParseErrorCode, msg, "dbs.qlParser.term")
+		}
+		var conds []string
+		for i, op := range []string{">=", "", "<="} {
+			if op == "" || arr[i] == "*" {
+				continue
+			}
+			val, err := p.value(key, arr[i])
+			if err != nil {
+				return "", err
+			}
+			conds = append(conds, fmt.Sprintf("%s %s %s", key.Column, op, p.bind(val)))
+		}
+		if len(conds) == 0 {
+			msg := fmt.Sprintf("range '%s' should have at least one bound", tok.value)
+			return "", Error(InvalidParamErr, ParseErrorCode, msg, "dbs.qlParser.term")
+		}
+		cond = strings.Join(conds, " AND ")
+	} else if strings.Contains(tok.value, "*") {
+		if key.Type != "string" {
+			msg := fmt.Sprintf("wildcard is not supported for %s key", key.Key)
+			return "", Error(InvalidParamErr, ParseErrorCode, msg, "dbs.qlParser.term")
+		}
+		op, val := OperatorValue(tok.value)
+		cond = fmt.Sprintf("%s %s %s", key.Column, strings.ToUpper(op), p.bind(val))
+	} else {
+		val, err := p.value(key, tok.value)
+		if err != nil {
+			return "", err
+		}
+		cond = fmt.Sprintf("%s = %s", key.Column, p.bind(val))
+	}
+	if key.Key == "status" {
+		p.status = true
+	}
+	if key.Join == "" {
+		return "(" + cond + ")", nil
+	}
+	tmpl := make(map[string]any)
+	tmpl["Owner"] = DBOWNER
+	tmpl["Ids"] = true
+	tmpl[key.Join] = true
+	stm, err := LoadTemplateSQL("select_dataset", tmpl)
+	if err != nil {
+		return "", Error(err, LoadErrorCode, "", "dbs.qlParser.term")
+	}
+	return fmt.Sprintf("d.dataset_id IN (%s)", WhereClause(stm, []string{cond})), nil
+}
+
+// helper function to convert value of term to type of its key
+func (p *qlParser) value(key QLKey, val string) (interface{}, error) {
+	if key.Type == "int" {
+		num, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			msg := fmt.Sprintf("invalid value '%s' of %s key, should be integer", val, key.Key)
+			return nil, Error(InvalidParamErr, ParseErrorCode, msg, "dbs.qlParser.value")
+		}
+		return num, nil
+	}
+	if key.Key == "status" {
+		return strings.ToUpper(val), nil
+	}
+	return val, nil
+}
+
+// helper function to add bind value and get its placeholder
+func (p *qlParser) bind(val interface{}) string {
+	p.args = append(p.args, val)
+	return placeholder(fmt.Sprintf("ql%d", len(p.args)))
+}
@@ -41,7 +41,7 @@ func (a *API) GetSite() error {
 	stm = WhereClause(stm, conds)
 
 	// use generic query API to fetch the results from DB
-	err = executeAll(a.Writer, a.Separator, stm, args...)
+	err = a.executePage(stm, args...)
 	if err != nil {
 		return Error(err, QueryErrorCode, "", "dbs.sites.Sites")
 	}
@@ -100,7 +100,8 @@ func (r *Sites) Insert(tx *sql.Tx) (int64, error) {
 	} else if Verbose > 1 {
 		log.Printf("Insert Sites\n%s\n%+v", stm, r)
 	}
-	_, err = tx.Exec(
+	r.SITE_ID, err = execInsert(
+		tx,
 		stm,
 		r.SITE_ID,
 		r.SITE,
@@ -2,28 +2,105 @@ package dbs
 
 import (
 	"bytes"
-	"path/filepath"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
 	"text/template"
 )
 
-// consume list of templates and release their full path counterparts
-func fileNames(tdir string, filenames ...string) []string {
-	flist := []string{}
-	for _, fname := range filenames {
-		flist = append(flist, filepath.Join(tdir, fname))
+// StaticFS represents static area (sql and schema) compiled into DBS server,
+// files located in StaticDir take precedence over embedded ones which allows
+// to use on-disk static area for development
+var StaticFS fs.FS
+
+// staticFiles represents overlay of StaticDir on top of StaticFS
+type staticFiles struct{}
+
+// Open implements fs.FS interface
+func (staticFiles) Open(name string) (fs.File, error) {
+	if StaticDir != "" {
+		if f, err := os.DirFS(StaticDir).Open(name); err == nil {
+			return f, nil
+		}
+	}
+	if StaticFS == nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return StaticFS.Open(name)
+}
+
+// ReadDir implements fs.ReadDirFS interface, it merges entries of
+// on-disk and embedded static areas
+func (staticFiles) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries := make(map[string]fs.DirEntry)
+	var lastErr error
+	if StaticFS != nil {
+		if list, err := fs.ReadDir(StaticFS, name); err == nil {
+			for _, e := range list {
+				entries[e.Name()] = e
+			}
+		} else {
+			lastErr = err
+		}
+	}
+	if StaticDir != "" {
+		if list, err := fs.ReadDir(os.DirFS(StaticDir), name); err == nil {
+			for _, e := range list {
+				entries[e.Name()] = e
+			}
+		} else if len(entries) == 0 {
+			lastErr = err
+		}
+	}
+	if len(entries) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	var out []fs.DirEntry
+	for _, e := range entries {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name() < out[j].Name() })
+	return out, nil
+}
+
+// ReadStaticFile reads file of static area, e.g. schema/sqlite.sql
+func ReadStaticFile(name string) ([]byte, error) {
+	return fs.ReadFile(staticFiles{}, name)
+}
+
+// ListStaticFiles lists files of given directory of static area
+func ListStaticFiles(dir string) ([]string, error) {
+	entries, err := fs.ReadDir(staticFiles{}, dir)
+	if err != nil {
+		return nil, err
 	}
-	return flist
+	var out []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			out = append(out, e.Name())
+		}
+	}
+	return out, nil
 }
 
-// ParseTmpl parses template with given data
+// ParseTmpl parses template of given directory of static area with given data
 func ParseTmpl(tdir, tmpl string, data interface{}) (string, error) {
 	buf := new(bytes.Buffer)
-	filenames := fileNames(tdir, tmpl)
-	t := template.Must(template.ParseFiles(filenames...))
-	err := t.Execute(buf, data)
+	content, err := ReadStaticFile(path.Join(tdir, tmpl))
+	if err != nil {
+		msg := "unable to read template file"
+		return "", Error(err, ReaderErrorCode, msg, "dbs.ParseTmpl")
+	}
+	t, err := template.New(tmpl).Parse(string(content))
+	if err != nil {
+		msg := "unable to parse template file"
+		return "", Error(err, ParseErrorCode, msg, "dbs.ParseTmpl")
+	}
+	err = t.Execute(buf, data)
 	if err != nil {
 		msg := "unable to parse template files"
-		return "", Error(err, ReaderErrorCode, msg, "dbs.API.UpdateOsInfo")
+		return "", Error(err, ReaderErrorCode, msg, "dbs.ParseTmpl")
 	}
 	return buf.String(), nil
 }
@@ -28,13 +28,14 @@ func HttpClient(tout int) *http.Client {
 	return &http.Client{}
 }
 
-// ReplaceBinds replaces given pattern in string
+// ReplaceBinds replaces named :bind placeholders of given statement with
+// positional ? placeholders, PostgreSQL :: type casts are left untouched
 func ReplaceBinds(stm string) string {
-	regexp, err := regexp.Compile(`:[a-zA-Z_0-9]+`)
+	regexp, err := regexp.Compile(`(^|[^:]):[a-zA-Z_0-9]+`)
 	if err != nil {
 		log.Fatal(err)
 	}
-	match := regexp.ReplaceAllString(stm, "?")
+	match := regexp.ReplaceAllString(stm, "${1}?")
 	return match
 }
 
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/CHESSComputing/DataBookkeeping/dbs"
+)
+
+// TestFullTextCondition tests full-text conditions of DB back-ends
+func TestFullTextCondition(t *testing.T) {
+	dbsOwner := dbs.DBOWNER
+	defer func() {
+		dbs.DBOWNER = dbsOwner
+	}()
+
+	tests := []struct {
+		owner, cond string
+		args        []interface{}
+	}{
+		{"sqlite", "c.config_id IN (SELECT docid FROM configs_fts WHERE configs_fts MATCH ?)",
+			[]interface{}{`"/data/calib.h5" "12.5"`}},
+		{"mysql", "MATCH(c.content_text) AGAINST (? IN BOOLEAN MODE)",
+			[]interface{}{`+"/data/calib.h5" +"12.5"`}},
+		{"postgres", "to_tsvector('simple', COALESCE(c.content::text, '')) @@ plainto_tsquery('simple', ?)",
+			[]interface{}{"/data/calib.h5 12.5"}},
+	}
+	for _, v := range tests {
+		dbs.DBOWNER = v.owner
+		cond, args, err := dbs.FullTextCondition("configs", "c", ` /data/calib.h5  "12.5"`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.TrimSpace(cond) != v.cond {
+			t.Errorf("wrong %s condition %s, expect %s", v.owner, cond, v.cond)
+		}
+		if fmt.Sprintf("%v", args) != fmt.Sprintf("%v", v.args) {
+			t.Errorf("wrong %s args %v, expect %v", v.owner, args, v.args)
+		}
+	}
+
+	dbs.DBOWNER = "sqlite"
+	if _, _, err := dbs.FullTextCondition("configs", "c", ` "" `); err == nil {
+		t.Error("empty full-text query should fail")
+	}
+	if _, _, err := dbs.FullTextCondition("files", "f", "abc"); err == nil {
+		t.Error("full-text query of files should fail")
+	}
+}
@@ -6,8 +6,10 @@ package main
 import (
 	"bytes"
 	"compress/gzip"
-	"errors"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"hash"
 	"io"
 	"io/ioutil"
 	"log"
@@ -38,6 +40,11 @@ func FileHandler(c *gin.Context) {
 	ApiHandler(c, "file")
 }
 
+// FileInvalidateHandler provides access to /file/invalidate end-point
+func FileInvalidateHandler(c *gin.Context) {
+	ApiHandler(c, "file_invalidate")
+}
+
 // OsinfoHandler provides access to /osinfo end-point
 func OsinfoHandler(c *gin.Context) {
 	ApiHandler(c, "osinfo")
@@ -65,14 +72,49 @@ func PackageHandler(c *gin.Context) {
 
 // DatasetHandler provides access to GET /datasets and /dataset/:name end-point
 func DatasetHandler(c *gin.Context) {
+	// /datasets/history end-point shares /datasets/*name route
+	if c.Request.Method != "DELETE" && c.Param("name") == "/history" {
+		DatasetHistoryHandler(c)
+		return
+	}
 	ApiHandler(c, "dataset")
 }
 
+// DatasetHistoryHandler provides access to /datasets/history end-point
+func DatasetHistoryHandler(c *gin.Context) {
+	ApiHandler(c, "dataset_history")
+}
+
+// DatasetStatusHandler provides access to /dataset/status end-point
+func DatasetStatusHandler(c *gin.Context) {
+	ApiHandler(c, "dataset_status")
+}
+
+// DatasetBulkHandler provides access to /datasets/bulk end-point
+func DatasetBulkHandler(c *gin.Context) {
+	ApiHandler(c, "dataset_bulk")
+}
+
+// LineageHandler provides access to /lineage end-point
+func LineageHandler(c *gin.Context) {
+	ApiHandler(c, "lineage")
+}
+
 // ProvenanceHandler provides access to /provenance and /provenance/:did end-point
 func ProvenanceHandler(c *gin.Context) {
 	ApiHandler(c, "provenance")
 }
 
+// SearchHandler provides access to /search end-point
+func SearchHandler(c *gin.Context) {
+	ApiHandler(c, "search")
+}
+
+// AuditHandler provides access to /audit end-point
+func AuditHandler(c *gin.Context) {
+	ApiHandler(c, "audit")
+}
+
 // ApiHandler represents generic API handler for GET/POST/PUT/DELETE requests of a specific API
 func ApiHandler(c *gin.Context, api string) {
 	r := c.Request
@@ -87,6 +129,32 @@ func ApiHandler(c *gin.Context, api string) {
 	}
 }
 
+// acceptFormats maps HTTP Accept header values to output formats of DBS APIs
+var acceptFormats = map[string]string{
+	"text/vnd.graphviz": "dot",
+	"text/vnd.mermaid":  "mermaid",
+
+	"application/provenance+json": "prov-json",
+	"application/ld+json":         "jsonld",
+	"text/turtle":                 "turtle",
+}
+
+// helper function to get output format from HTTP Accept header
+func acceptFormat(r *http.Request) string {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		accept = strings.TrimSpace(strings.Split(accept, ";")[0])
+		if format, ok := acceptFormats[accept]; ok {
+			return format
+		}
+	}
+	return ""
+}
+
+// helper function to check if given content type represents NDJSON stream
+func isNDJSON(ctype string) bool {
+	return ctype == "application/ndjson" || ctype == "application/x-ndjson"
+}
+
 // helper function to get DBS API
 func getApi(c *gin.Context, a string) (*dbs.API, error) {
 	r := c.Request
@@ -104,18 +172,17 @@ func getApi(c *gin.Context, a string) (*dbs.API, error) {
 
 	var api *dbs.API
 	params := make(map[string]any)
-	if r.Method == "GET" {
-		// for example /file?dataset=/x/y/z we'll parse URL query
-		// r.URL.Query() returns map[string][]string
-		for k, values := range r.URL.Query() {
-			var vals []string
-			for _, v := range values {
-				vals = append(vals, v)
-			}
-			params[k] = vals
+	// for example /file?dataset=/x/y/z we'll parse URL query, POST/PUT
+	// APIs may also carry URL parameters, e.g. /datasets/bulk?batch_size=10
+	// r.URL.Query() returns map[string][]string
+	for k, values := range r.URL.Query() {
+		var vals []string
+		for _, v := range values {
+			vals = append(vals, v)
 		}
+		params[k] = vals
 	}
-	if r.Method == "GET" || r.Method == "DELETE" {
+	if r.Method == "GET" || r.Method == "HEAD" || r.Method == "DELETE" {
 		api = &dbs.API{
 			Writer:      w,
 			Params:      params,
@@ -126,11 +193,11 @@ func getApi(c *gin.Context, a string) (*dbs.API, error) {
 	} else { // all other HTTP requests POST/PUT may contain payload
 
 		headerContentType := r.Header.Get("Content-Type")
-		if headerContentType != "application/json" {
+		if headerContentType != "application/json" && !(a == "dataset_bulk" && isNDJSON(headerContentType)) {
 			msg := fmt.Sprintf("unsupported Content-Type: '%s'", headerContentType)
 			e := dbs.Error(dbs.ContentTypeErr, dbs.ContentTypeErrorCode, msg, "web.DBSPostHandler")
 			responseMsg(w, r, e, http.StatusUnsupportedMediaType)
-			return nil, errors.New(msg)
+			return nil, e
 		}
 		defer r.Body.Close()
 		//         var params dbs.Record
@@ -149,7 +216,7 @@ func getApi(c *gin.Context, a string) (*dbs.API, error) {
 				log.Println(msg, err)
 				e := dbs.Error(err, dbs.ReaderErrorCode, msg, "web.DBSPostHandler")
 				responseMsg(w, r, e, http.StatusInternalServerError)
-				return nil, errors.New(msg)
+				return nil, e
 			}
 			body = utils.GzipReader{reader, r.Body}
 		} else {
@@ -159,7 +226,7 @@ func getApi(c *gin.Context, a string) (*dbs.API, error) {
 				log.Println(msg, err)
 				e := dbs.Error(err, dbs.ReaderErrorCode, msg, "web.DBSPostHandler")
 				responseMsg(w, r, e, http.StatusInternalServerError)
-				return nil, errors.New(msg)
+				return nil, e
 			}
 			body = ioutil.NopCloser(bytes.NewBuffer(data))
 		}
@@ -221,8 +288,22 @@ func DBSGetHandler(c *gin.Context, a string) {
 	if err != nil {
 		responseMsg(w, r, err, http.StatusBadRequest)
 	}
+	// HEAD requests provide number of records of GET APIs
+	if r.Method == "HEAD" {
+		api.Params["count"] = "true"
+	}
+	// lineage and provenance APIs support different output formats
+	if a == "lineage" || a == "provenance" {
+		if format := acceptFormat(r); format != "" {
+			if _, ok := api.Params["format"]; !ok {
+				api.Params["format"] = format
+			}
+		}
+	}
 	if a == "dataset" {
 		err = api.GetDataset()
+	} else if a == "dataset_history" {
+		err = api.GetDatasetHistory()
 	} else if a == "provenance" {
 		err = api.GetProvenance()
 	} else if a == "file" {
@@ -231,6 +312,8 @@ func DBSGetHandler(c *gin.Context, a string) {
 		err = api.GetChild()
 	} else if a == "parent" {
 		err = api.GetParent()
+	} else if a == "lineage" {
+		err = api.GetLineage()
 	} else if a == "osinfo" {
 		err = api.GetOsInfo()
 	} else if a == "environment" {
@@ -241,6 +324,10 @@ func DBSGetHandler(c *gin.Context, a string) {
 		err = api.GetConfig()
 	} else if a == "package" {
 		err = api.GetPackage()
+	} else if a == "audit" {
+		err = api.GetAudit()
+	} else if a == "search" {
+		err = api.Search()
 	} else {
 		err = dbs.NotImplementedApiErr
 	}
@@ -260,10 +347,15 @@ func DBSPostHandler(c *gin.Context, a string) {
 	w := c.Writer
 	api, err := getApi(c, a)
 	if err != nil {
+		auditApi(c, a, nil, nil, err)
 		responseMsg(w, r, err, http.StatusBadRequest)
+		return
 	}
+	payload := newPayloadHash(api)
 	if a == "dataset" {
 		err = api.InsertDataset()
+	} else if a == "dataset_bulk" {
+		err = api.InsertDatasets()
 	} else if a == "file" {
 		err = api.InsertFile()
 	} else if a == "parent" {
@@ -281,6 +373,7 @@ func DBSPostHandler(c *gin.Context, a string) {
 	} else {
 		err = dbs.NotImplementedApiErr
 	}
+	auditApi(c, a, api, payload, err)
 	if err != nil {
 		responseMsg(w, r, err, http.StatusBadRequest)
 		return
@@ -295,13 +388,20 @@ func DBSPutHandler(c *gin.Context, a string) {
 	w := c.Writer
 	api, err := getApi(c, a)
 	if err != nil {
+		auditApi(c, a, nil, nil, err)
 		responseMsg(w, r, err, http.StatusBadRequest)
+		return
 	}
+	payload := newPayloadHash(api)
 	if a == "dataset" {
 		err = api.UpdateDataset()
 	} else if a == "file" {
 		err = api.UpdateFile()
-	} else if a == "Parent" {
+	} else if a == "dataset_status" {
+		err = api.UpdateDatasetStatus()
+	} else if a == "file_invalidate" {
+		err = api.InvalidateFile()
+	} else if a == "parent" {
 		err = api.UpdateParent()
 	} else if a == "osinfo" {
 		err = api.UpdateOsInfo()
@@ -312,6 +412,7 @@ func DBSPutHandler(c *gin.Context, a string) {
 	} else {
 		err = dbs.NotImplementedApiErr
 	}
+	auditApi(c, a, api, payload, err)
 	if err != nil {
 		responseMsg(w, r, err, http.StatusBadRequest)
 		return
@@ -326,8 +427,11 @@ func DBSDeleteHandler(c *gin.Context, a string) {
 	w := c.Writer
 	api, err := getApi(c, a)
 	if err != nil {
+		auditApi(c, a, nil, nil, err)
 		responseMsg(w, r, err, http.StatusBadRequest)
+		return
 	}
+	payload := newPayloadHash(api)
 	if a == "dataset" {
 		err = api.DeleteDataset()
 	} else if a == "file" {
@@ -343,8 +447,44 @@ func DBSDeleteHandler(c *gin.Context, a string) {
 	} else {
 		err = dbs.NotImplementedApiErr
 	}
+	auditApi(c, a, api, payload, err)
 	if err != nil {
 		responseMsg(w, r, err, http.StatusBadRequest)
 		return
 	}
 }
+
+// helper function to compute hash of API payload while API reads it
+func newPayloadHash(api *dbs.API) hash.Hash {
+	if api.Reader == nil {
+		return nil
+	}
+	h := sha256.New()
+	api.Reader = io.TeeReader(api.Reader, h)
+	return h
+}
+
+// helper function to record audit log entry of POST/PUT/DELETE API call
+func auditApi(c *gin.Context, a string, api *dbs.API, payload hash.Hash, err error) {
+	r := c.Request
+	rec := dbs.AuditRecord{
+		Api:        a,
+		Method:     r.Method,
+		Uri:        r.URL.RequestURI(),
+		CreateBy:   createBy(r),
+		RemoteAddr: c.ClientIP(),
+		Result:     dbs.AuditSuccess,
+	}
+	if payload != nil {
+		// read remaining payload which API did not consume
+		io.Copy(io.Discard, api.Reader)
+		rec.PayloadHash = hex.EncodeToString(payload.Sum(nil))
+	}
+	if err != nil {
+		rec.Result = dbs.AuditFailure
+		rec.Code = dbs.ErrorCode(err)
+	}
+	if e := dbs.InsertAudit(rec); e != nil {
+		log.Printf("unable to write audit log of API %s, error %v", a, e)
+	}
+}
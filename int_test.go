@@ -24,6 +24,8 @@ type TestCase struct {
 	Verbose      int      `json:"verbose"`       // verbosity level
 	Fail         bool     `json:"fail"`          // should test fail
 	DumpResponse bool     `json:"dump_response"` // enable dump of the response
+
+	Headers map[string]string `json:"headers"` // expected response header patterns
 }
 
 // run test workflow for a single endpoint
@@ -58,15 +60,36 @@ func runTestWorkflow(t *testing.T, v TestCase) {
 			msg := fmt.Sprintf("ERROR: wrong response code, expect=%d received=%d", v.Code, rr.Code)
 			t.Fatal(msg)
 		}
+		// check response headers
+		for key, o := range v.Headers {
+			pat, err := regexp.Compile(o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if val := rr.Header().Get(key); !pat.MatchString(val) {
+				msg := fmt.Sprintf("Header %s pattern '%s' does not match received value '%s'", key, o, val)
+				t.Fatal(msg)
+			}
+		}
 
 		// check response
 		var d []map[string]any
-		if v.Method == "GET" {
+		if v.Method == "GET" || (v.Method != "POST" && len(v.Output) > 0) {
 			data := rr.Body.Bytes()
-			err = json.Unmarshal(data, &d)
-			//             err = json.NewDecoder(rr.Body).Decode(&d)
-			if err != nil {
-				t.Fatalf("Failed to decode body, %v", err)
+			// non JSON outputs, e.g. graph formats, are checked by patterns only
+			// while JSON based formats, e.g. JSON-LD, are JSON objects
+			ctype := rr.Header().Get("Content-Type")
+			if strings.HasSuffix(ctype, "+json") {
+				var obj map[string]any
+				if err := json.Unmarshal(data, &obj); err != nil {
+					t.Fatalf("Failed to decode body, %v", err)
+				}
+			} else if !strings.HasPrefix(ctype, "text/") {
+				err = json.Unmarshal(data, &d)
+				//             err = json.NewDecoder(rr.Body).Decode(&d)
+				if err != nil {
+					t.Fatalf("Failed to decode body, %v", err)
+				}
 			}
 			// check output patterns
 			for _, o := range v.Output {
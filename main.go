@@ -23,6 +23,12 @@ func main() {
 	cfile := os.Getenv("FOXDEN_CONFIG")
 	var config string
 	flag.StringVar(&config, "config", cfile, "server config file, default $FOXDEN_CONFIG")
+	var migrate, migrateStatus, rollback bool
+	flag.BoolVar(&migrate, "migrate", false, "apply pending database schema migrations")
+	flag.BoolVar(&migrateStatus, "migrate-status", false, "show status of database schema migrations")
+	flag.BoolVar(&rollback, "rollback", false, "rollback last applied database schema migration")
+	var bootstrap bool
+	flag.BoolVar(&bootstrap, "bootstrap", false, "create database schema at startup if database is empty, overrides Bootstrap config option")
 	flag.Parse()
 	if version {
 		fmt.Println("server version:", srvConfig.Info())
@@ -33,8 +39,29 @@ func main() {
 	} else {
 		log.Fatal(fmt.Sprintf("Unable to parse config='%s'\nerror: %v", config, err))
 	}
+	// bootstrap option of config file can be overwritten by -bootstrap flag
+	if val, err := configBootstrap(config); err == nil {
+		BootstrapSchema = val
+	} else {
+		log.Fatal(fmt.Sprintf("Unable to read Bootstrap option of config='%s'\nerror: %v", config, err))
+	}
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "bootstrap" {
+			BootstrapSchema = bootstrap
+		}
+	})
 	if srvConfig.Config.DataBookkeeping.WebServer.Verbose > 0 {
 		log.SetFlags(log.Llongfile)
 	}
+	if migrate {
+		MigrateSchema("migrate")
+		return
+	} else if rollback {
+		MigrateSchema("rollback")
+		return
+	} else if migrateStatus {
+		MigrateSchema("status")
+		return
+	}
 	Server()
 }
@@ -52,8 +52,14 @@ func initDBS(dryRun bool, dburi string) *sql.DB {
 	dbs.StaticDir = "static"
 	dbtype := "sqlite3"
 	dbowner := "sqlite"
+	// tests can run against PostgreSQL, e.g.
+	// DBS_DB_TYPE=postgres DBS_DB_FILE="postgres://user@localhost/dbs?sslmode=disable"
+	if os.Getenv("DBS_DB_TYPE") == "postgres" {
+		dbtype = "postgres"
+		dbowner = "postgres"
+	}
 
-	db, err := sql.Open(dbtype, dburi)
+	db, err := sql.Open(dbs.DriverName(dbtype), dbs.DataSourceName(dbtype, dburi))
 	if err != nil {
 		log.Fatal("unable to open db file", err)
 	}
@@ -89,22 +95,42 @@ func initServer() {
 		routes := []server.Route{
 			// GET APIs for integration tests
 			server.Route{Method: "GET", Path: "/datasets", Handler: DatasetHandler, Authorized: false},
+			server.Route{Method: "GET", Path: "/datasets/*name", Handler: DatasetHandler, Authorized: false},
 			server.Route{Method: "GET", Path: "/files", Handler: FileHandler, Authorized: false},
 			server.Route{Method: "GET", Path: "/scripts", Handler: ScriptHandler, Authorized: false},
 			server.Route{Method: "GET", Path: "/configs", Handler: ConfigHandler, Authorized: false},
 			server.Route{Method: "GET", Path: "/packages", Handler: PackageHandler, Authorized: false},
 			server.Route{Method: "GET", Path: "/environments", Handler: EnvironmentHandler, Authorized: false},
 			server.Route{Method: "GET", Path: "/provenance", Handler: ProvenanceHandler, Authorized: false},
+			server.Route{Method: "GET", Path: "/search", Handler: SearchHandler, Authorized: false},
+			server.Route{Method: "GET", Path: "/parents", Handler: ParentHandler, Authorized: false},
+			server.Route{Method: "GET", Path: "/lineage", Handler: LineageHandler, Authorized: false},
+			server.Route{Method: "GET", Path: "/audit", Handler: AuditHandler, Authorized: false},
 
 			// POST APIs for integration tests
 			server.Route{Method: "POST", Path: "/provenance", Handler: ProvenanceHandler, Authorized: false},
 			server.Route{Method: "POST", Path: "/dataset", Handler: DatasetHandler, Authorized: false},
+			server.Route{Method: "POST", Path: "/datasets/bulk", Handler: DatasetBulkHandler, Authorized: false},
 			server.Route{Method: "POST", Path: "/file", Handler: FileHandler, Authorized: false},
 			server.Route{Method: "POST", Path: "/script", Handler: ScriptHandler, Authorized: false},
 			server.Route{Method: "POST", Path: "/config", Handler: ConfigHandler, Authorized: false},
 			server.Route{Method: "POST", Path: "/environment", Handler: EnvironmentHandler, Authorized: false},
+			server.Route{Method: "POST", Path: "/parent", Handler: ParentHandler, Authorized: false},
+
+			// PUT APIs for integration tests
+			server.Route{Method: "PUT", Path: "/dataset", Handler: DatasetHandler, Authorized: false},
+			server.Route{Method: "PUT", Path: "/dataset/status", Handler: DatasetStatusHandler, Authorized: false},
+			server.Route{Method: "PUT", Path: "/file", Handler: FileHandler, Authorized: false},
+			server.Route{Method: "PUT", Path: "/file/invalidate", Handler: FileInvalidateHandler, Authorized: false},
+			server.Route{Method: "PUT", Path: "/parent", Handler: ParentHandler, Authorized: false},
+
+			// DELETE APIs for integration tests
+			server.Route{Method: "DELETE", Path: "/dataset/*name", Handler: DatasetHandler, Authorized: false},
+			server.Route{Method: "DELETE", Path: "/file/*name", Handler: FileHandler, Authorized: false},
+			server.Route{Method: "DELETE", Path: "/parent/*name", Handler: ParentHandler, Authorized: false},
 		}
 		router = server.Router(routes, nil, "static", srvConfig.Config.DataBookkeeping.WebServer)
+		headRoutes(router, routes)
 	}
 }
 
@@ -137,7 +163,7 @@ func responseRecorder(t *testing.T, v TestCase) *httptest.ResponseRecorder {
 		t.Fatal(err)
 	}
 	req.Header.Add("Accept", "application/json")
-	if v.Method == "POST" {
+	if v.Method == "POST" || v.Method == "PUT" {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
@@ -0,0 +1,60 @@
+package main
+
+// DBS schema migration tool
+//
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/CHESSComputing/DataBookkeeping/dbs"
+	srvConfig "github.com/CHESSComputing/golib/config"
+)
+
+// MigrateSchema performs given schema migration action: migrate applies all
+// pending migrations, status prints status of migrations and rollback
+// reverts last applied migration
+func MigrateSchema(action string) {
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+	Verbose = srvConfig.Config.DataBookkeeping.WebServer.Verbose
+	dbs.Verbose = Verbose
+	dbs.StaticDir = srvConfig.Config.DataBookkeeping.WebServer.StaticDir
+	initDB()
+	defer dbs.DB.Close()
+
+	switch action {
+	case "migrate":
+		migrations, err := dbs.Migrate()
+		for _, m := range migrations {
+			fmt.Printf("applied migration %04d %s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("database schema version %d\n", dbs.CurrentSchemaVersion())
+	case "rollback":
+		m, err := dbs.Rollback()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("rolled back migration %04d %s\n", m.Version, m.Name)
+		fmt.Printf("database schema version %d\n", dbs.CurrentSchemaVersion())
+	default:
+		records, err := dbs.GetMigrationStatus()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("database schema version %d, server schema version %d\n",
+			dbs.CurrentSchemaVersion(), dbs.SchemaVersion)
+		for _, rec := range records {
+			status := "pending"
+			if rec.Applied {
+				status = "applied"
+				if rec.AppliedAt > 0 {
+					status = fmt.Sprintf("applied %s", time.Unix(rec.AppliedAt, 0).UTC().Format(time.RFC3339))
+				}
+			}
+			fmt.Printf("%04d %-20s %s\n", rec.Version, rec.Name, status)
+		}
+	}
+}
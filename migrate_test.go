@@ -0,0 +1,173 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/CHESSComputing/DataBookkeeping/dbs"
+)
+
+// TestMigrations tests rollback and migration of DBS schema
+func TestMigrations(t *testing.T) {
+	// preserve DBS settings used by other tests
+	dbsDB, dbsOwner, dbsSQL, staticDir := dbs.DB, dbs.DBOWNER, dbs.DBSQL, dbs.StaticDir
+	defer func() {
+		dbs.DB, dbs.DBOWNER, dbs.DBSQL, dbs.StaticDir = dbsDB, dbsOwner, dbsSQL, staticDir
+	}()
+
+	tdb, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "migrations.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tdb.Close()
+	dbs.DB = tdb
+	dbs.DBOWNER = "sqlite"
+	// use static area embedded into the server
+	dbs.StaticDir = ""
+	dbs.DBSQL = dbs.LoadSQL("sqlite")
+
+	// empty database does not have any schema
+	if version := dbs.CurrentSchemaVersion(); version != 0 {
+		t.Fatalf("wrong schema version of empty database %d", version)
+	}
+	if _, err := dbs.Migrate(); err == nil {
+		t.Fatal("migration of empty database should fail")
+	}
+
+	// create latest schema
+	data, err := dbs.ReadStaticFile("schema/sqlite.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stm := range dbs.SplitStatements(string(data)) {
+		if _, err := tdb.Exec(stm); err != nil {
+			t.Fatalf("unable to execute %s, error %v", stm, err)
+		}
+	}
+	if version := dbs.CurrentSchemaVersion(); version != dbs.SchemaVersion {
+		t.Fatalf("wrong schema version %d, expect %d", version, dbs.SchemaVersion)
+	}
+	if err := dbs.CheckSchemaVersion(); err != nil {
+		t.Fatal(err)
+	}
+
+	// rollback all migrations down to baseline schema
+	for version := dbs.SchemaVersion; version > dbs.BaselineSchemaVersion; version-- {
+		m, err := dbs.Rollback()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if m.Version != version {
+			t.Fatalf("wrong rolled back migration %d, expect %d", m.Version, version)
+		}
+	}
+	if _, err := dbs.Rollback(); err == nil {
+		t.Fatal("rollback of baseline schema should fail")
+	}
+	if err := dbs.CheckSchemaVersion(); err == nil {
+		t.Fatal("check of older schema should fail")
+	}
+	if _, err := tdb.Exec("SELECT COUNT(*) FROM audit_log"); err == nil {
+		t.Fatal("audit_log table should be removed by rollback")
+	}
+
+	// database without schema versioning is at baseline version
+	if _, err := tdb.Exec("DROP TABLE schema_version"); err != nil {
+		t.Fatal(err)
+	}
+	if version := dbs.CurrentSchemaVersion(); version != dbs.BaselineSchemaVersion {
+		t.Fatalf("wrong schema version %d of database without versioning", version)
+	}
+
+	// migrate to latest schema
+	migrations, err := dbs.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != dbs.SchemaVersion-dbs.BaselineSchemaVersion {
+		t.Fatalf("wrong number of applied migrations %d", len(migrations))
+	}
+	if err := dbs.CheckSchemaVersion(); err != nil {
+		t.Fatal(err)
+	}
+	records, err := dbs.GetMigrationStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range records {
+		if !rec.Applied {
+			t.Fatalf("migration %+v is not applied", rec)
+		}
+	}
+	if _, err := tdb.Exec("SELECT status FROM datasets"); err != nil {
+		t.Fatal(err)
+	}
+	if applied, err := dbs.Migrate(); err != nil || len(applied) != 0 {
+		t.Fatalf("migration of latest schema should be no-op, applied %v error %v", applied, err)
+	}
+}
+
+// TestBootstrap tests creation of DBS schema in empty database
+func TestBootstrap(t *testing.T) {
+	// preserve DBS settings used by other tests
+	dbsDB, dbsOwner, dbsSQL, staticDir := dbs.DB, dbs.DBOWNER, dbs.DBSQL, dbs.StaticDir
+	defer func() {
+		dbs.DB, dbs.DBOWNER, dbs.DBSQL, dbs.StaticDir = dbsDB, dbsOwner, dbsSQL, staticDir
+	}()
+
+	dburi := dbs.DataSourceName("sqlite3", filepath.Join(t.TempDir(), "bootstrap.db"))
+	tdb, err := sql.Open("sqlite3", dburi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tdb.Close()
+	dbs.DB = tdb
+	dbs.DBOWNER = "sqlite"
+	dbs.StaticDir = ""
+	dbs.DBSQL = dbs.LoadSQL("sqlite")
+
+	created, err := dbs.Bootstrap()
+	if err != nil || !created {
+		t.Fatalf("schema of empty database is not created, error %v", err)
+	}
+	if err := dbs.CheckSchemaVersion(); err != nil {
+		t.Fatal(err)
+	}
+	// database with schema is left untouched
+	if created, err := dbs.Bootstrap(); err != nil || created {
+		t.Fatalf("bootstrap of existing schema should be no-op, created %v error %v", created, err)
+	}
+
+	// foreign keys are enforced
+	var fk int
+	if err := tdb.QueryRow("PRAGMA foreign_keys").Scan(&fk); err != nil || fk != 1 {
+		t.Fatalf("foreign keys are not enabled, value %d error %v", fk, err)
+	}
+	if _, err := tdb.Exec("INSERT INTO datasets_files (dataset_id, file_id, file_type) VALUES (1, 1, 'input')"); err == nil {
+		t.Fatal("insert of dangling reference should fail")
+	}
+}
+
+// TestConfigBootstrap tests bootstrap option of YAML and JSON config files
+func TestConfigBootstrap(t *testing.T) {
+	tests := map[string]bool{
+		"DataBookkeeping:\n  DBFile: sqlite3:///tmp/dbs.db\n  Bootstrap: true\n":      true,
+		"DataBookkeeping:\n  DBFile: sqlite3:///tmp/dbs.db\n":                         false,
+		`{"DataBookkeeping": {"DBFile": "sqlite3:///tmp/dbs.db", "Bootstrap": true}}`: true,
+	}
+	for content, expect := range tests {
+		fname := filepath.Join(t.TempDir(), "config")
+		if err := os.WriteFile(fname, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		val, err := configBootstrap(fname)
+		if err != nil || val != expect {
+			t.Errorf("wrong bootstrap option %v of config %s, error %v", val, content, err)
+		}
+	}
+	if val, err := configBootstrap(""); err != nil || val {
+		t.Errorf("wrong bootstrap option %v without config, error %v", val, err)
+	}
+}
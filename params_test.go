@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/CHESSComputing/DataBookkeeping/dbs"
+)
+
+// TestAddParam tests conditions of multi-value and negated query parameters
+func TestAddParam(t *testing.T) {
+	dbsOwner := dbs.DBOWNER
+	defer func() {
+		dbs.DBOWNER = dbsOwner
+	}()
+	dbs.DBOWNER = "sqlite"
+
+	tests := []struct {
+		values []string
+		cond   string
+		args   []interface{}
+	}{
+		{[]string{"A"}, " s.site = ?", []interface{}{"A"}},
+		{[]string{"A*"}, " s.site like ?", []interface{}{"A%"}},
+		{[]string{"A", "B"}, " s.site IN (?, ?)", []interface{}{"A", "B"}},
+		{[]string{"A", "B*"}, " (s.site like ? OR s.site = ?)", []interface{}{"B%", "A"}},
+		{[]string{"!A"}, " s.site <> ?", []interface{}{"A"}},
+		{[]string{"!A", "!B*"}, " (s.site not like ? AND s.site <> ?)", []interface{}{"B%", "A"}},
+		{[]string{"!A", "!B"}, " s.site NOT IN (?, ?)", []interface{}{"A", "B"}},
+	}
+	for _, v := range tests {
+		params := map[string]any{"site": v.values}
+		conds, args := dbs.AddParam("site", "s.site", params, []string{}, []interface{}{})
+		if len(conds) != 1 || conds[0] != v.cond {
+			t.Errorf("wrong conditions of %v: %v, expect %s", v.values, conds, v.cond)
+		}
+		if fmt.Sprintf("%v", args) != fmt.Sprintf("%v", v.args) {
+			t.Errorf("wrong args of %v: %v, expect %v", v.values, args, v.args)
+		}
+	}
+
+	// positive and negated values provide separate conditions
+	params := map[string]any{"site": []string{"A*", "!AB"}}
+	conds, args := dbs.AddParam("site", "s.site", params, []string{}, []interface{}{})
+	if strings.Join(conds, " AND") != " s.site like ? AND s.site <> ?" || len(args) != 2 {
+		t.Errorf("wrong conditions %v args %v", conds, args)
+	}
+
+	// long lists are passed via token generator
+	var values []string
+	for i := 0; i <= dbs.TokenThreshold; i++ {
+		values = append(values, fmt.Sprintf("site%d", i))
+	}
+	params = map[string]any{"site": values}
+	conds, args = dbs.AddParam("site", "s.site", params, []string{}, []interface{}{})
+	if len(conds) != 1 || !strings.HasPrefix(conds[0], " s.site IN (WITH TOKEN_GENERATOR AS") ||
+		!strings.Contains(conds[0], "SELECT token FROM TOKEN_GENERATOR") || len(args) != 1 {
+		t.Errorf("wrong conditions of long list %v args %d", conds, len(args))
+	}
+	dbs.DBOWNER = "postgres"
+	conds, args = dbs.AddParam("site", "s.site", params, []string{}, []interface{}{})
+	if len(conds) != 1 || strings.Contains(conds[0], "TOKEN_GENERATOR") || len(args) != len(values) {
+		t.Errorf("wrong conditions of long list %v args %d", conds, len(args))
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/CHESSComputing/DataBookkeeping/dbs"
+)
+
+// TestPostgresBinds tests conversion of DBS placeholders into PostgreSQL ones
+func TestPostgresBinds(t *testing.T) {
+	tests := []struct {
+		stm, expect string
+	}{
+		{"SELECT did FROM datasets", "SELECT did FROM datasets"},
+		{"SELECT did FROM datasets WHERE did = ?", "SELECT did FROM datasets WHERE did = $1"},
+		{"UPDATE files SET size = ? WHERE file = ? AND file_type = ?",
+			"UPDATE files SET size = $1 WHERE file = $2 AND file_type = $3"},
+		{"SELECT '?' AS q, \"a?\" FROM t WHERE x = ?", "SELECT '?' AS q, \"a?\" FROM t WHERE x = $1"},
+	}
+	for _, v := range tests {
+		if stm := dbs.Rebind(v.stm); stm != v.expect {
+			t.Errorf("wrong rebind of %s: %s, expect %s", v.stm, stm, v.expect)
+		}
+	}
+
+	// named binds of static SQL are converted to positional ones while
+	// PostgreSQL type casts are preserved
+	stm := dbs.ReplaceBinds("SELECT :did::text, x FROM t WHERE a = :a AND b=:b")
+	if expect := "SELECT ?::text, x FROM t WHERE a = ? AND b=?"; stm != expect {
+		t.Errorf("wrong statement %s, expect %s", stm, expect)
+	}
+	if stm := dbs.Rebind(stm); stm != "SELECT $1::text, x FROM t WHERE a = $2 AND b=$3" {
+		t.Errorf("wrong rebind of %s", stm)
+	}
+
+	for _, dbtype := range []string{"postgres", "PostgreSQL"} {
+		if name := dbs.DriverName(dbtype); name != dbs.PostgresDriver {
+			t.Errorf("wrong driver %s of %s", name, dbtype)
+		}
+	}
+	if name := dbs.DriverName("sqlite3"); name != "sqlite3" {
+		t.Errorf("wrong driver %s of sqlite3", name)
+	}
+}
+
+// TestPostgresSchema tests PostgreSQL schema and migration files
+func TestPostgresSchema(t *testing.T) {
+	dbsOwner, staticDir := dbs.DBOWNER, dbs.StaticDir
+	defer func() {
+		dbs.DBOWNER, dbs.StaticDir = dbsOwner, staticDir
+	}()
+	dbs.DBOWNER = "postgres"
+	dbs.StaticDir = ""
+
+	data, err := dbs.ReadStaticFile("schema/postgres.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// function body should be kept within single statement
+	var function string
+	for _, stm := range dbs.SplitStatements(string(data)) {
+		if strings.HasPrefix(stm, "CREATE FUNCTION") {
+			function = stm
+		}
+	}
+	if !strings.HasSuffix(function, "$$ LANGUAGE plpgsql") || !strings.Contains(function, "RAISE EXCEPTION") {
+		t.Fatalf("wrong function statement %q", function)
+	}
+
+	migrations, err := dbs.LoadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := migrations[len(migrations)-1]; last.Version != dbs.SchemaVersion {
+		t.Fatalf("wrong last migration %+v", last)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CHESSComputing/DataBookkeeping/dbs"
+)
+
+// TestProvAgentType tests PROV type of agents of user and server records
+func TestProvAgentType(t *testing.T) {
+	_, restore := initTestDB(t, "prov.db")
+	defer restore()
+
+	tests := []struct {
+		did      string
+		createBy string
+		agent    string
+	}{
+		{"/beamline=3a/btr=prov/cycle=2024-3/sample_name=user", "user", "prov:Person"},
+		{"/beamline=3a/btr=prov/cycle=2024-3/sample_name=server", "", "prov:SoftwareAgent"},
+	}
+	for _, v := range tests {
+		rec := map[string]any{
+			"did":        v.did,
+			"osinfo":     map[string]any{"name": "linux-cc7", "kernel": "1-2-3", "version": "cc7-123"},
+			"processing": "prov-processing",
+			"site":       "Cornell",
+		}
+		data, err := json.Marshal(rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		api := dbs.API{
+			Reader:      bytes.NewReader(data),
+			Writer:      httptest.NewRecorder(),
+			ContentType: "application/json",
+			Params:      make(map[string]any),
+			CreateBy:    v.createBy,
+			Api:         "dataset",
+		}
+		if err := api.InsertDataset(); err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		api = dbs.API{Writer: w, Params: make(map[string]any), Api: "provenance"}
+		if err := api.GetProv(v.did, "prov-json"); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(w.Body.String(), `"prov:type": "`+v.agent+`"`) {
+			t.Errorf("provenance of %s created by '%s' should have %s agent:\n%s",
+				v.did, v.createBy, v.agent, w.Body.String())
+		}
+	}
+}
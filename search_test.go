@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/CHESSComputing/DataBookkeeping/dbs"
+)
+
+// TestParseQuery tests translation of DBS query language into SQL conditions
+func TestParseQuery(t *testing.T) {
+	dbsOwner, staticDir := dbs.DBOWNER, dbs.StaticDir
+	defer func() {
+		dbs.DBOWNER, dbs.StaticDir = dbsOwner, staticDir
+	}()
+	dbs.DBOWNER = "sqlite"
+	dbs.StaticDir = ""
+
+	tests := []struct {
+		query    string
+		args     []interface{}
+		contains []string
+	}{
+		{"did:/beamline=3a/*", []interface{}{"/beamline=3a/%", "INVALID"},
+			[]string{"(d.did LIKE ?)", "d.status <> ?"}},
+		{"did:/beamline=3a/* AND package:numpy AND site:Cornell",
+			[]interface{}{"/beamline=3a/%", "numpy", "Cornell", "INVALID"},
+			[]string{"(d.did LIKE ?) AND d.dataset_id IN (", "pk.name = ?", "s.site = ?"}},
+		{"site:Cornell OR NOT (osname:linux* package:numpy)",
+			[]interface{}{"Cornell", "linux%", "numpy", "INVALID"},
+			[]string{" OR NOT (", "o.name LIKE ?"}},
+		{"create_at:[100 TO 200] status:invalid", []interface{}{int64(100), int64(200), "INVALID"},
+			[]string{"d.create_at >= ? AND d.create_at <= ?", "d.status = ?"}},
+		{"modify_at:[* TO 200]", []interface{}{int64(200), "INVALID"},
+			[]string{"(d.modify_at <= ?)"}},
+		{`script:"my script" or file:/data/*.h5`, []interface{}{"my script", "/data/%.h5", "INVALID"},
+			[]string{"sc.name = ?", "f.file LIKE ?"}},
+	}
+	for _, v := range tests {
+		cond, args, err := dbs.ParseQuery(v.query)
+		if err != nil {
+			t.Errorf("unable to parse query %s, error %v", v.query, err)
+			continue
+		}
+		if fmt.Sprintf("%v", args) != fmt.Sprintf("%v", v.args) {
+			t.Errorf("wrong args of query %s: %v, expect %v", v.query, args, v.args)
+		}
+		for _, s := range v.contains {
+			if !strings.Contains(cond, s) {
+				t.Errorf("condition of query %s does not contain %s:\n%s", v.query, s, cond)
+			}
+		}
+	}
+
+	for _, query := range []string{
+		"numpy",
+		"foo:bar",
+		"did:",
+		"did:/a AND",
+		"(did:/a OR site:Cornell",
+		"did:/a)",
+		`script:"abc`,
+		"create_at:[1 TO",
+		"create_at:[a TO b]",
+		"create_at:[* TO *]",
+		"create_at:12*",
+	} {
+		if _, _, err := dbs.ParseQuery(query); err == nil {
+			t.Errorf("invalid query %s should fail", query)
+		}
+	}
+}
@@ -19,6 +19,7 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"github.com/CHESSComputing/DataBookkeeping/dbs"
 	srvConfig "github.com/CHESSComputing/golib/config"
@@ -27,6 +28,7 @@ import (
 	sqldb "github.com/CHESSComputing/golib/sqldb"
 	"github.com/gin-gonic/gin"
 	validator "github.com/go-playground/validator/v10"
+	yaml "github.com/goccy/go-yaml"
 
 	// GO profiler
 	_ "net/http/pprof"
@@ -35,6 +37,36 @@ import (
 // Verbose controls verbosity level
 var Verbose int
 
+// BootstrapSchema controls creation of database schema at server startup
+// when database is empty, see Bootstrap option of DataBookkeeping config
+// section and -bootstrap flag which overrides it
+var BootstrapSchema bool
+
+// serverOptions represents options of DataBookkeeping config section which
+// are not part of golib server configuration
+type serverOptions struct {
+	DataBookkeeping struct {
+		Bootstrap bool `yaml:"Bootstrap"`
+	} `yaml:"DataBookkeeping"`
+}
+
+// helper function to read bootstrap option from DataBookkeeping section of
+// given config file, YAML parser reads JSON config files as well
+func configBootstrap(fname string) (bool, error) {
+	if fname == "" {
+		return false, nil
+	}
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		return false, err
+	}
+	var opts serverOptions
+	if err := yaml.Unmarshal(data, &opts); err != nil {
+		return false, err
+	}
+	return opts.DataBookkeeping.Bootstrap, nil
+}
+
 // helper function to setup our router
 func setupRouter() *gin.Engine {
 	routes := []server.Route{
@@ -42,6 +74,7 @@ func setupRouter() *gin.Engine {
 		// GET APIs should use plural name as for look-up of multiple records
 		// exception is osinfo
 		{Method: "GET", Path: "/datasets", Handler: DatasetHandler, Authorized: false},
+		// /datasets/*name route also serves /datasets/history end-point
 		{Method: "GET", Path: "/datasets/*name", Handler: DatasetHandler, Authorized: false},
 
 		{Method: "GET", Path: "/files", Handler: FileHandler, Authorized: false},
@@ -52,6 +85,8 @@ func setupRouter() *gin.Engine {
 		{Method: "GET", Path: "/children", Handler: ChildHandler, Authorized: false},
 		{Method: "GET", Path: "/child", Handler: ChildHandler, Authorized: false},
 
+		{Method: "GET", Path: "/lineage", Handler: LineageHandler, Authorized: false},
+
 		{Method: "GET", Path: "/osinfo", Handler: OsinfoHandler, Authorized: false},
 		{Method: "GET", Path: "/environments", Handler: EnvironmentHandler, Authorized: false},
 		{Method: "GET", Path: "/scripts", Handler: ScriptHandler, Authorized: false},
@@ -60,6 +95,7 @@ func setupRouter() *gin.Engine {
 		{Method: "GET", Path: "/configs/*name", Handler: ConfigHandler, Authorized: false},
 
 		{Method: "GET", Path: "/provenance", Handler: ProvenanceHandler, Authorized: false},
+		{Method: "GET", Path: "/search", Handler: SearchHandler, Authorized: false},
 
 		// authorized routes
 		// POST/PUT/DELETE routes should use single name as we operate with single record
@@ -67,12 +103,15 @@ func setupRouter() *gin.Engine {
 
 		// dataset routes
 		{Method: "POST", Path: "/dataset", Handler: DatasetHandler, Authorized: true, Scope: "write"},
+		{Method: "POST", Path: "/datasets/bulk", Handler: DatasetBulkHandler, Authorized: true, Scope: "write"},
 		{Method: "PUT", Path: "/dataset", Handler: DatasetHandler, Authorized: true, Scope: "write"},
+		{Method: "PUT", Path: "/dataset/status", Handler: DatasetStatusHandler, Authorized: true, Scope: "write"},
 		{Method: "DELETE", Path: "/dataset/*name", Handler: DatasetHandler, Authorized: true, Scope: "delete"},
 
 		// file routes
 		{Method: "POST", Path: "/file", Handler: FileHandler, Authorized: true, Scope: "write"},
 		{Method: "PUT", Path: "/file", Handler: FileHandler, Authorized: true, Scope: "write"},
+		{Method: "PUT", Path: "/file/invalidate", Handler: FileInvalidateHandler, Authorized: true, Scope: "write"},
 		{Method: "DELETE", Path: "/file/*name", Handler: FileHandler, Authorized: true, Scope: "delete"},
 
 		// parent routes
@@ -97,29 +136,34 @@ func setupRouter() *gin.Engine {
 
 		// config routes
 		{Method: "POST", Path: "/config", Handler: ConfigHandler, Authorized: true, Scope: "write"},
+
+		// audit log of POST/PUT/DELETE APIs is available to admins only
+		{Method: "GET", Path: "/audit", Handler: AuditHandler, Authorized: true, Scope: "admin"},
 	}
 	r := server.Router(routes, nil, "static", srvConfig.Config.DataBookkeeping.WebServer)
+	headRoutes(r, routes)
 	return r
 }
 
-// Server defines our HTTP server
-func Server() {
-	// be verbose
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
-
-	// initialize record validator
-	dbs.RecordValidator = validator.New()
-	Verbose = srvConfig.Config.DataBookkeeping.WebServer.Verbose
-	dbs.Verbose = Verbose
-	dbs.StaticDir = srvConfig.Config.DataBookkeeping.WebServer.StaticDir
+// helper function to add HEAD routes for all public GET routes, HEAD requests
+// return number of records of GET APIs via X-Total-Count header
+func headRoutes(r *gin.Engine, routes []server.Route) {
+	for _, route := range routes {
+		if route.Method == "GET" && !route.Authorized {
+			r.HEAD(route.Path, route.Handler)
+		}
+	}
+}
 
-	// set database connection once
+// helper function to setup DBS database connection and SQL statements
+func initDB() {
 	log.Println("parse Config.DBFile:", srvConfig.Config.DataBookkeeping.DBFile)
 	dbtype, dburi, dbowner := sqldb.ParseDBFile(srvConfig.Config.DataBookkeeping.DBFile)
 	log.Printf("InitDB: type=%s owner=%s", dbtype, dbowner)
 
-	// setup DBS
-	db, dberr := sqldb.InitDB(dbtype, dburi)
+	// setup DBS, PostgreSQL connections use DBS driver which rebinds
+	// placeholders of SQL statements
+	db, dberr := sqldb.InitDB(dbs.DriverName(dbtype), dbs.DataSourceName(dbtype, dburi))
 	if dberr != nil {
 		log.Fatal(dberr)
 	}
@@ -128,8 +172,39 @@ func Server() {
 	dbsql := dbs.LoadSQL(dbowner)
 	dbs.DBSQL = dbsql
 	dbs.DBOWNER = dbowner
+}
+
+// Server defines our HTTP server
+func Server() {
+	// be verbose
+	log.SetFlags(log.LstdFlags | log.Lshortfile)
+
+	// initialize record validator
+	dbs.RecordValidator = validator.New()
+	Verbose = srvConfig.Config.DataBookkeeping.WebServer.Verbose
+	dbs.Verbose = Verbose
+	dbs.StaticDir = srvConfig.Config.DataBookkeeping.WebServer.StaticDir
+
+	// set database connection once
+	initDB()
 	defer dbs.DB.Close()
 
+	// create schema of empty database if requested
+	if BootstrapSchema {
+		created, err := dbs.Bootstrap()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if created {
+			log.Printf("created database schema version %d", dbs.CurrentSchemaVersion())
+		}
+	}
+
+	// refuse to run against database with older schema
+	if err := dbs.CheckSchemaVersion(); err != nil {
+		log.Fatal(err)
+	}
+
 	// load Lexicon patterns
 	lexPatterns, err := lexicon.LoadPatterns(srvConfig.Config.DataBookkeeping.LexiconFile)
 	if err != nil {
@@ -137,6 +212,12 @@ func Server() {
 	}
 	lexicon.LexiconPatterns = lexPatterns
 
+	// load keys of query language used by search API
+	dbs.QLKeys, err = dbs.LoadQLKeys()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// setup web router and start the service
 	r := setupRouter()
 	webServer := srvConfig.Config.DataBookkeeping.WebServer
@@ -0,0 +1,25 @@
+package main
+
+// DBS static area
+//
+import (
+	"embed"
+	"io/fs"
+	"log"
+
+	"github.com/CHESSComputing/DataBookkeeping/dbs"
+)
+
+// SQL statements, DB schemas and query language keys are compiled into the
+// server, therefore it can run from any working directory without static area
+//
+//go:embed static/sql static/schema static/ql_keys.json
+var staticArea embed.FS
+
+func init() {
+	sub, err := fs.Sub(staticArea, "static")
+	if err != nil {
+		log.Fatal("unable to load embedded static area", err)
+	}
+	dbs.StaticFS = sub
+}
@@ -0,0 +1,13 @@
+-- dataset history keeps snapshots of previous provenance of datasets
+CREATE TABLE `datasets_history` (
+  `history_id` int(11) NOT NULL AUTO_INCREMENT,
+  `dataset_id` int(11) NOT NULL,
+  `did` varchar(255) NOT NULL,
+  `version` int(11) NOT NULL,
+  `provenance` longtext,
+  `create_at` int(11) DEFAULT NULL,
+  `create_by` varchar(255) DEFAULT NULL,
+  PRIMARY KEY (`history_id`),
+  UNIQUE KEY `did_version` (`did`,`version`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+CREATE INDEX idx_datasets_history_did ON datasets_history(did);
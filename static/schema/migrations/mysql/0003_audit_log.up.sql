@@ -0,0 +1,20 @@
+-- audit log keeps append-only record of every POST/PUT/DELETE API call
+CREATE TABLE `audit_log` (
+  `audit_id` int(11) NOT NULL AUTO_INCREMENT,
+  `api` varchar(255) NOT NULL,
+  `method` varchar(16) NOT NULL,
+  `uri` text,
+  `create_by` varchar(255) DEFAULT NULL,
+  `remote_addr` varchar(255) DEFAULT NULL,
+  `payload_hash` varchar(64) DEFAULT NULL,
+  `result` varchar(16) NOT NULL,
+  `code` int(11) DEFAULT 0,
+  `create_at` int(11) DEFAULT NULL,
+  PRIMARY KEY (`audit_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+CREATE TRIGGER `audit_log_no_update` BEFORE UPDATE ON `audit_log` FOR EACH ROW
+  SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_log is append-only';
+CREATE TRIGGER `audit_log_no_delete` BEFORE DELETE ON `audit_log` FOR EACH ROW
+  SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_log is append-only';
+CREATE INDEX idx_audit_log_api ON audit_log(api);
+CREATE INDEX idx_audit_log_create_at ON audit_log(create_at);
@@ -0,0 +1,5 @@
+ALTER TABLE datasets
+    DROP FOREIGN KEY `fk_datasets_status`,
+    DROP KEY `fk_datasets_status`,
+    DROP COLUMN `status`;
+DROP TABLE IF EXISTS dataset_statuses;
@@ -0,0 +1,12 @@
+-- dataset statuses define lifecycle of datasets
+CREATE TABLE `dataset_statuses` (
+  `status_id` int(11) NOT NULL AUTO_INCREMENT,
+  `status` varchar(16) NOT NULL,
+  PRIMARY KEY (`status_id`),
+  UNIQUE KEY `status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+INSERT INTO `dataset_statuses` (`status`) VALUES ('VALID'), ('INVALID'), ('DEPRECATED'), ('PRODUCTION');
+ALTER TABLE datasets
+    ADD COLUMN `status` varchar(16) NOT NULL DEFAULT 'VALID',
+    ADD KEY `fk_datasets_status` (`status`),
+    ADD CONSTRAINT `fk_datasets_status` FOREIGN KEY (`status`) REFERENCES `dataset_statuses` (`status`) ON UPDATE CASCADE;
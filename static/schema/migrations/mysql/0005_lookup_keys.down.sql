@@ -0,0 +1,8 @@
+ALTER TABLE osinfo
+    DROP KEY `idx_osinfo_record`;
+ALTER TABLE environments
+    DROP KEY `idx_environments_name`,
+    ADD KEY `idx_environments_name` (`name`);
+ALTER TABLE scripts
+    DROP KEY `idx_scripts_name`,
+    ADD KEY `idx_scripts_name` (`name`);
@@ -0,0 +1,11 @@
+-- records of lookup tables are identified by unique keys which allow DBS
+-- server to get or insert them concurrently, existing duplicates should be
+-- merged before this migration is applied
+ALTER TABLE scripts
+    DROP KEY `idx_scripts_name`,
+    ADD UNIQUE KEY `idx_scripts_name` (`name`);
+ALTER TABLE environments
+    DROP KEY `idx_environments_name`,
+    ADD UNIQUE KEY `idx_environments_name` (`name`);
+ALTER TABLE osinfo
+    ADD UNIQUE KEY `idx_osinfo_record` (`name`,`version`,`kernel`);
@@ -0,0 +1,6 @@
+ALTER TABLE configs
+    DROP KEY `idx_configs_fts`;
+ALTER TABLE scripts
+    DROP KEY `idx_scripts_fts`;
+ALTER TABLE environments
+    DROP KEY `idx_environments_fts`;
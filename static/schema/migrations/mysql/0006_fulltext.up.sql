@@ -0,0 +1,7 @@
+-- full-text indexes of free-form text of configs, scripts and environments
+ALTER TABLE configs
+    ADD FULLTEXT KEY `idx_configs_fts` (`content`);
+ALTER TABLE scripts
+    ADD FULLTEXT KEY `idx_scripts_fts` (`options`);
+ALTER TABLE environments
+    ADD FULLTEXT KEY `idx_environments_fts` (`details`);
@@ -0,0 +1,10 @@
+-- JSON objects and arrays are stored as quoted JSON strings
+ALTER TABLE configs
+    DROP KEY `idx_configs_fts`,
+    DROP COLUMN `content_text`;
+ALTER TABLE configs
+    MODIFY `content` text;
+UPDATE configs SET content = JSON_QUOTE(content)
+    WHERE CASE WHEN JSON_VALID(content) THEN JSON_TYPE(content) IN ('OBJECT', 'ARRAY') ELSE 0 END;
+ALTER TABLE configs
+    ADD FULLTEXT KEY `idx_configs_fts` (`content`);
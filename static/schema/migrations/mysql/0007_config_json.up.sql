@@ -0,0 +1,18 @@
+-- configs are stored as JSON documents instead of quoted JSON strings,
+-- content which is not valid JSON document is stored as JSON string.
+-- JSON columns can't be part of FULLTEXT index, therefore full-text index
+-- of configs refers to generated text column.
+UPDATE configs SET content = NULL WHERE content = 'NULL';
+UPDATE configs SET content = JSON_UNQUOTE(content)
+    WHERE CASE WHEN JSON_VALID(content)
+        THEN JSON_TYPE(content) = 'STRING' AND JSON_VALID(JSON_UNQUOTE(content))
+        ELSE 0 END;
+UPDATE configs SET content = JSON_QUOTE(content)
+    WHERE content IS NOT NULL AND NOT JSON_VALID(content);
+ALTER TABLE configs
+    DROP KEY `idx_configs_fts`;
+ALTER TABLE configs
+    MODIFY `content` json DEFAULT NULL;
+ALTER TABLE configs
+    ADD COLUMN `content_text` longtext GENERATED ALWAYS AS (CAST(`content` AS CHAR)) STORED,
+    ADD FULLTEXT KEY `idx_configs_fts` (`content_text`);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_datasets_history_did;
+DROP TABLE IF EXISTS datasets_history;
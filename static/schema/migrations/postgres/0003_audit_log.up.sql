@@ -0,0 +1,24 @@
+-- audit log keeps append-only record of every POST/PUT/DELETE API call
+CREATE TABLE audit_log (
+    audit_id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    api VARCHAR(255) NOT NULL,
+    method VARCHAR(16) NOT NULL,
+    uri TEXT,
+    create_by VARCHAR(255),
+    remote_addr VARCHAR(255),
+    payload_hash VARCHAR(64),
+    result VARCHAR(16) NOT NULL,
+    code INTEGER DEFAULT 0,
+    create_at BIGINT
+);
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+    FOR EACH ROW EXECUTE PROCEDURE audit_log_append_only();
+CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+    FOR EACH ROW EXECUTE PROCEDURE audit_log_append_only();
+CREATE INDEX idx_audit_log_api ON audit_log(api);
+CREATE INDEX idx_audit_log_create_at ON audit_log(create_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_datasets_status;
+ALTER TABLE datasets DROP COLUMN status;
+DROP TABLE IF EXISTS dataset_statuses;
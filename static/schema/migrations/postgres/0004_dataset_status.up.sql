@@ -0,0 +1,9 @@
+-- dataset statuses define lifecycle of datasets
+CREATE TABLE dataset_statuses (
+    status_id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    status VARCHAR(16) NOT NULL UNIQUE
+);
+INSERT INTO dataset_statuses (status) VALUES ('VALID'), ('INVALID'), ('DEPRECATED'), ('PRODUCTION');
+ALTER TABLE datasets ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'VALID'
+    REFERENCES dataset_statuses(status) ON UPDATE CASCADE;
+CREATE INDEX idx_datasets_status ON datasets(status);
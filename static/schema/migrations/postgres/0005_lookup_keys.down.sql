@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_osinfo_record;
+DROP INDEX IF EXISTS idx_environments_name;
+CREATE INDEX idx_environments_name ON environments(name);
+DROP INDEX IF EXISTS idx_scripts_name;
+CREATE INDEX idx_scripts_name ON scripts(name);
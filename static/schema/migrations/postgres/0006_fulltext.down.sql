@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_configs_fts;
+DROP INDEX IF EXISTS idx_scripts_fts;
+DROP INDEX IF EXISTS idx_environments_fts;
@@ -0,0 +1,5 @@
+-- full-text indexes of free-form text of configs, scripts and environments,
+-- DBS queries use the same tsvector expressions to benefit from them
+CREATE INDEX idx_configs_fts ON configs USING GIN (to_tsvector('simple', COALESCE(content, '')));
+CREATE INDEX idx_scripts_fts ON scripts USING GIN (to_tsvector('simple', COALESCE(options, '')));
+CREATE INDEX idx_environments_fts ON environments USING GIN (to_tsvector('simple', COALESCE(details, '')));
@@ -0,0 +1,6 @@
+-- JSON objects and arrays are stored as quoted JSON strings
+DROP INDEX IF EXISTS idx_configs_fts;
+ALTER TABLE configs ALTER COLUMN content TYPE TEXT USING
+    CASE WHEN jsonb_typeof(content) IN ('object', 'array') THEN to_jsonb(content::text)::text
+    ELSE content::text END;
+CREATE INDEX idx_configs_fts ON configs USING GIN (to_tsvector('simple', COALESCE(content, '')));
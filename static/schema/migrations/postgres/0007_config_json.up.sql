@@ -0,0 +1,24 @@
+-- configs are stored as JSONB documents instead of quoted JSON strings,
+-- content which is not valid JSON document is stored as JSON string
+CREATE FUNCTION dbs_config_json(val TEXT) RETURNS JSONB AS $$
+DECLARE
+    doc JSONB;
+BEGIN
+    doc := val::jsonb;
+    IF jsonb_typeof(doc) = 'string' THEN
+        BEGIN
+            doc := (doc #>> '{}')::jsonb;
+        EXCEPTION WHEN others THEN
+            NULL;
+        END;
+    END IF;
+    RETURN doc;
+EXCEPTION WHEN others THEN
+    RETURN to_jsonb(val);
+END;
+$$ LANGUAGE plpgsql;
+DROP INDEX IF EXISTS idx_configs_fts;
+UPDATE configs SET content = NULL WHERE content = 'NULL';
+ALTER TABLE configs ALTER COLUMN content TYPE JSONB USING dbs_config_json(content);
+DROP FUNCTION dbs_config_json(TEXT);
+CREATE INDEX idx_configs_fts ON configs USING GIN (to_tsvector('simple', COALESCE(content::text, '')));
@@ -0,0 +1,12 @@
+-- dataset history keeps snapshots of previous provenance of datasets
+CREATE TABLE datasets_history (
+    history_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    dataset_id INTEGER NOT NULL,
+    did VARCHAR(255) NOT NULL,
+    version INTEGER NOT NULL,
+    provenance TEXT,
+    create_at INTEGER,
+    create_by VARCHAR(255),
+    UNIQUE (did, version)
+);
+CREATE INDEX idx_datasets_history_did ON datasets_history(did);
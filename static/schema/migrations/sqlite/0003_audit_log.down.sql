@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS audit_log_no_update;
+DROP TRIGGER IF EXISTS audit_log_no_delete;
+DROP INDEX IF EXISTS idx_audit_log_api;
+DROP INDEX IF EXISTS idx_audit_log_create_at;
+DROP TABLE IF EXISTS audit_log;
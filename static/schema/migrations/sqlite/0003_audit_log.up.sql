@@ -0,0 +1,23 @@
+-- audit log keeps append-only record of every POST/PUT/DELETE API call
+CREATE TABLE audit_log (
+    audit_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    api VARCHAR(255) NOT NULL,
+    method VARCHAR(16) NOT NULL,
+    uri TEXT,
+    create_by VARCHAR(255),
+    remote_addr VARCHAR(255),
+    payload_hash VARCHAR(64),
+    result VARCHAR(16) NOT NULL,
+    code INTEGER DEFAULT 0,
+    create_at INTEGER
+);
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+CREATE INDEX idx_audit_log_api ON audit_log(api);
+CREATE INDEX idx_audit_log_create_at ON audit_log(create_at);
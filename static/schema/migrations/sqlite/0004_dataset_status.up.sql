@@ -0,0 +1,10 @@
+-- dataset statuses define lifecycle of datasets
+CREATE TABLE dataset_statuses (
+    status_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    status VARCHAR(16) NOT NULL UNIQUE
+);
+INSERT INTO dataset_statuses (status) VALUES ('VALID'), ('INVALID'), ('DEPRECATED'), ('PRODUCTION');
+-- SQLite does not allow to add column with foreign key and non-NULL
+-- default value, therefore status values are validated by DBS server
+ALTER TABLE datasets ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'VALID';
+CREATE INDEX idx_datasets_status ON datasets(status);
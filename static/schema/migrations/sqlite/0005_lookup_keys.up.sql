@@ -0,0 +1,8 @@
+-- records of lookup tables are identified by unique keys which allow DBS
+-- server to get or insert them concurrently, existing duplicates should be
+-- merged before this migration is applied
+DROP INDEX IF EXISTS idx_scripts_name;
+CREATE UNIQUE INDEX idx_scripts_name ON scripts(name);
+DROP INDEX IF EXISTS idx_environments_name;
+CREATE UNIQUE INDEX idx_environments_name ON environments(name);
+CREATE UNIQUE INDEX idx_osinfo_record ON osinfo(name, version, kernel);
@@ -0,0 +1,15 @@
+DROP TRIGGER IF EXISTS configs_fts_bu;
+DROP TRIGGER IF EXISTS configs_fts_bd;
+DROP TRIGGER IF EXISTS configs_fts_au;
+DROP TRIGGER IF EXISTS configs_fts_ai;
+DROP TABLE IF EXISTS configs_fts;
+DROP TRIGGER IF EXISTS scripts_fts_bu;
+DROP TRIGGER IF EXISTS scripts_fts_bd;
+DROP TRIGGER IF EXISTS scripts_fts_au;
+DROP TRIGGER IF EXISTS scripts_fts_ai;
+DROP TABLE IF EXISTS scripts_fts;
+DROP TRIGGER IF EXISTS environments_fts_bu;
+DROP TRIGGER IF EXISTS environments_fts_bd;
+DROP TRIGGER IF EXISTS environments_fts_au;
+DROP TRIGGER IF EXISTS environments_fts_ai;
+DROP TABLE IF EXISTS environments_fts;
@@ -0,0 +1,57 @@
+-- full-text indexes of free-form text of configs, scripts and environments,
+-- FTS4 external content tables refer to rows of indexed tables by their ids
+-- and are kept in sync by triggers
+CREATE VIRTUAL TABLE configs_fts USING fts4(content="configs", content);
+CREATE TRIGGER configs_fts_bu BEFORE UPDATE ON configs
+BEGIN
+    DELETE FROM configs_fts WHERE docid = old.config_id;
+END;
+CREATE TRIGGER configs_fts_bd BEFORE DELETE ON configs
+BEGIN
+    DELETE FROM configs_fts WHERE docid = old.config_id;
+END;
+CREATE TRIGGER configs_fts_au AFTER UPDATE ON configs
+BEGIN
+    INSERT INTO configs_fts (docid, content) VALUES (new.config_id, new.content);
+END;
+CREATE TRIGGER configs_fts_ai AFTER INSERT ON configs
+BEGIN
+    INSERT INTO configs_fts (docid, content) VALUES (new.config_id, new.content);
+END;
+INSERT INTO configs_fts (configs_fts) VALUES ('rebuild');
+CREATE VIRTUAL TABLE scripts_fts USING fts4(content="scripts", options);
+CREATE TRIGGER scripts_fts_bu BEFORE UPDATE ON scripts
+BEGIN
+    DELETE FROM scripts_fts WHERE docid = old.script_id;
+END;
+CREATE TRIGGER scripts_fts_bd BEFORE DELETE ON scripts
+BEGIN
+    DELETE FROM scripts_fts WHERE docid = old.script_id;
+END;
+CREATE TRIGGER scripts_fts_au AFTER UPDATE ON scripts
+BEGIN
+    INSERT INTO scripts_fts (docid, options) VALUES (new.script_id, new.options);
+END;
+CREATE TRIGGER scripts_fts_ai AFTER INSERT ON scripts
+BEGIN
+    INSERT INTO scripts_fts (docid, options) VALUES (new.script_id, new.options);
+END;
+INSERT INTO scripts_fts (scripts_fts) VALUES ('rebuild');
+CREATE VIRTUAL TABLE environments_fts USING fts4(content="environments", details);
+CREATE TRIGGER environments_fts_bu BEFORE UPDATE ON environments
+BEGIN
+    DELETE FROM environments_fts WHERE docid = old.environment_id;
+END;
+CREATE TRIGGER environments_fts_bd BEFORE DELETE ON environments
+BEGIN
+    DELETE FROM environments_fts WHERE docid = old.environment_id;
+END;
+CREATE TRIGGER environments_fts_au AFTER UPDATE ON environments
+BEGIN
+    INSERT INTO environments_fts (docid, details) VALUES (new.environment_id, new.details);
+END;
+CREATE TRIGGER environments_fts_ai AFTER INSERT ON environments
+BEGIN
+    INSERT INTO environments_fts (docid, details) VALUES (new.environment_id, new.details);
+END;
+INSERT INTO environments_fts (environments_fts) VALUES ('rebuild');
@@ -0,0 +1,3 @@
+-- JSON objects and arrays are stored as quoted JSON strings
+UPDATE configs SET content = json_quote(content)
+    WHERE CASE WHEN json_valid(content) THEN json_type(content) IN ('object', 'array') ELSE 0 END;
@@ -0,0 +1,9 @@
+-- configs are stored as JSON documents instead of quoted JSON strings,
+-- content which is not valid JSON document is stored as JSON string
+UPDATE configs SET content = NULL WHERE content = 'NULL';
+UPDATE configs SET content = json_extract(content, '$')
+    WHERE CASE WHEN json_valid(content)
+        THEN json_type(content) = 'text' AND json_valid(json_extract(content, '$'))
+        ELSE 0 END;
+UPDATE configs SET content = json_quote(content)
+    WHERE content IS NOT NULL AND NOT json_valid(content);
@@ -51,9 +51,19 @@ CREATE TABLE `buckets` (
   CONSTRAINT `fk_buckets_dataset` FOREIGN KEY (`dataset_id`) REFERENCES `datasets` (`dataset_id`) ON UPDATE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
 
+-- dataset statuses define lifecycle of datasets, see DatasetStatusTransitions
+CREATE TABLE `dataset_statuses` (
+  `status_id` int(11) NOT NULL AUTO_INCREMENT,
+  `status` varchar(16) NOT NULL,
+  PRIMARY KEY (`status_id`),
+  UNIQUE KEY `status` (`status`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+INSERT INTO `dataset_statuses` (`status`) VALUES ('VALID'), ('INVALID'), ('DEPRECATED'), ('PRODUCTION');
+
 CREATE TABLE `datasets` (
   `dataset_id` int(11) NOT NULL AUTO_INCREMENT,
   `did` varchar(255) NOT NULL,
+  `status` varchar(16) NOT NULL DEFAULT 'VALID',
   `site_id` int(11) DEFAULT NULL,
   `processing_id` int(11) DEFAULT NULL,
   `os_id` int(11) DEFAULT NULL,
@@ -69,10 +79,12 @@ CREATE TABLE `datasets` (
   KEY `fk_datasets_processing` (`processing_id`),
   KEY `fk_datasets_os` (`os_id`),
   KEY `fk_datasets_sites` (`site_id`),
+  KEY `fk_datasets_status` (`status`),
   CONSTRAINT `fk_datasets_config` FOREIGN KEY (`config_id`) REFERENCES `configs` (`config_id`) ON UPDATE CASCADE,
   CONSTRAINT `fk_datasets_os` FOREIGN KEY (`os_id`) REFERENCES `osinfo` (`os_id`) ON UPDATE CASCADE,
   CONSTRAINT `fk_datasets_processing` FOREIGN KEY (`processing_id`) REFERENCES `processing` (`processing_id`) ON UPDATE CASCADE,
-  CONSTRAINT `fk_datasets_sites` FOREIGN KEY (`site_id`) REFERENCES `sites` (`site_id`) ON UPDATE CASCADE
+  CONSTRAINT `fk_datasets_sites` FOREIGN KEY (`site_id`) REFERENCES `sites` (`site_id`) ON UPDATE CASCADE,
+  CONSTRAINT `fk_datasets_status` FOREIGN KEY (`status`) REFERENCES `dataset_statuses` (`status`) ON UPDATE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
 
 CREATE TABLE `files` (
@@ -102,7 +114,8 @@ CREATE TABLE `osinfo` (
   PRIMARY KEY (`os_id`),
   KEY `idx_osinfo_name` (`name`),
   KEY `idx_osinfo_kernel` (`kernel`),
-  KEY `idx_osinfo_version` (`version`)
+  KEY `idx_osinfo_version` (`version`),
+  UNIQUE KEY `idx_osinfo_record` (`name`,`version`,`kernel`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
 
 CREATE TABLE `environments` (
@@ -118,7 +131,7 @@ CREATE TABLE `environments` (
   `modify_by` varchar(255) DEFAULT NULL,
   PRIMARY KEY (`environment_id`),
   KEY `os_id` (`os_id`),
-  KEY `idx_environments_name` (`name`),
+  UNIQUE KEY `idx_environments_name` (`name`),
   KEY `fk_environments_parent` (`parent_environment_id`),
   CONSTRAINT `environments_ibfk_1` FOREIGN KEY (`os_id`) REFERENCES `osinfo` (`os_id`) ON DELETE SET NULL ON UPDATE CASCADE,
   CONSTRAINT `fk_environments_parent` FOREIGN KEY (`parent_environment_id`) REFERENCES `environments` (`environment_id`) ON DELETE SET NULL ON UPDATE CASCADE
@@ -148,7 +161,7 @@ CREATE TABLE `scripts` (
   `modify_at` int(11) DEFAULT NULL,
   `modify_by` varchar(255) DEFAULT NULL,
   PRIMARY KEY (`script_id`),
-  KEY `idx_scripts_name` (`name`),
+  UNIQUE KEY `idx_scripts_name` (`name`),
   KEY `fk_scripts_parent` (`parent_script_id`),
   CONSTRAINT `fk_scripts_parent` FOREIGN KEY (`parent_script_id`) REFERENCES `scripts` (`script_id`) ON DELETE SET NULL ON UPDATE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
@@ -156,7 +169,8 @@ CREATE TABLE `scripts` (
 -- Configs
 CREATE TABLE `configs` (
   `config_id` int(11) NOT NULL AUTO_INCREMENT,
-  `content` text,
+  `content` json DEFAULT NULL,
+  `content_text` longtext GENERATED ALWAYS AS (CAST(`content` AS CHAR)) STORED,
   `create_at` int(11) DEFAULT NULL,
   `create_by` varchar(255) DEFAULT NULL,
   `modify_at` int(11) DEFAULT NULL,
@@ -222,13 +236,70 @@ CREATE TABLE `environments_packages` (
   CONSTRAINT `environments_packages_ibfk_2` FOREIGN KEY (`package_id`) REFERENCES `packages` (`package_id`) ON DELETE CASCADE ON UPDATE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
 
+-- dataset history keeps snapshots of previous provenance of datasets,
+-- snapshot is taken every time dataset is changed
+CREATE TABLE `datasets_history` (
+  `history_id` int(11) NOT NULL AUTO_INCREMENT,
+  `dataset_id` int(11) NOT NULL,
+  `did` varchar(255) NOT NULL,
+  `version` int(11) NOT NULL,
+  `provenance` longtext,
+  `create_at` int(11) DEFAULT NULL,
+  `create_by` varchar(255) DEFAULT NULL,
+  PRIMARY KEY (`history_id`),
+  UNIQUE KEY `did_version` (`did`,`version`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- audit log keeps append-only record of every POST/PUT/DELETE API call
+CREATE TABLE `audit_log` (
+  `audit_id` int(11) NOT NULL AUTO_INCREMENT,
+  `api` varchar(255) NOT NULL,
+  `method` varchar(16) NOT NULL,
+  `uri` text,
+  `create_by` varchar(255) DEFAULT NULL,
+  `remote_addr` varchar(255) DEFAULT NULL,
+  `payload_hash` varchar(64) DEFAULT NULL,
+  `result` varchar(16) NOT NULL,
+  `code` int(11) DEFAULT 0,
+  `create_at` int(11) DEFAULT NULL,
+  PRIMARY KEY (`audit_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+CREATE TRIGGER `audit_log_no_update` BEFORE UPDATE ON `audit_log` FOR EACH ROW
+  SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_log is append-only';
+CREATE TRIGGER `audit_log_no_delete` BEFORE DELETE ON `audit_log` FOR EACH ROW
+  SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_log is append-only';
+
+-- schema version keeps applied schema migrations, see static/schema/migrations,
+-- new schema changes should be added as migrations and recorded here
+CREATE TABLE `schema_version` (
+  `version` int(11) NOT NULL,
+  `name` varchar(255) NOT NULL,
+  `applied_at` int(11) DEFAULT NULL,
+  PRIMARY KEY (`version`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+INSERT INTO schema_version (version, name, applied_at) VALUES
+    (1, 'baseline', UNIX_TIMESTAMP()),
+    (2, 'datasets_history', UNIX_TIMESTAMP()),
+    (3, 'audit_log', UNIX_TIMESTAMP()),
+    (4, 'dataset_status', UNIX_TIMESTAMP()),
+    (5, 'lookup_keys', UNIX_TIMESTAMP()),
+    (6, 'fulltext', UNIX_TIMESTAMP()),
+    (7, 'config_json', UNIX_TIMESTAMP());
+
 -- indexes
 CREATE INDEX idx_datasets_did ON datasets(did);
 CREATE INDEX idx_files_file ON files(file);
-CREATE INDEX idx_scripts_name ON scripts(name);
-CREATE INDEX idx_environments_name ON environments(name);
+CREATE UNIQUE INDEX idx_scripts_name ON scripts(name);
+CREATE UNIQUE INDEX idx_environments_name ON environments(name);
 CREATE INDEX idx_packages_name ON packages(name);
 CREATE INDEX idx_processing_name ON processing(processing);
 CREATE INDEX idx_osinfo_name ON osinfo(name);
 CREATE INDEX idx_osinfo_kernel ON osinfo(kernel);
 CREATE INDEX idx_osinfo_version ON osinfo(version);
+CREATE UNIQUE INDEX idx_osinfo_record ON osinfo(name, version, kernel);
+CREATE INDEX idx_datasets_history_did ON datasets_history(did);
+CREATE INDEX idx_audit_log_api ON audit_log(api);
+CREATE INDEX idx_audit_log_create_at ON audit_log(create_at);
+CREATE FULLTEXT INDEX idx_configs_fts ON configs(content_text);
+CREATE FULLTEXT INDEX idx_scripts_fts ON scripts(options);
+CREATE FULLTEXT INDEX idx_environments_fts ON environments(details);
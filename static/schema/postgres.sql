@@ -0,0 +1,246 @@
+-- DBS schema for PostgreSQL (v10+), primary keys are identity columns and
+-- DBS server obtains new ids from their sequences, see dbs.SequenceNextID
+
+CREATE TABLE processing (
+    processing_id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    processing VARCHAR(255) NOT NULL UNIQUE,
+    create_at BIGINT,
+    create_by VARCHAR(255),
+    modify_at BIGINT,
+    modify_by VARCHAR(255)
+);
+
+CREATE TABLE sites (
+    site_id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    site VARCHAR(255) NOT NULL UNIQUE,
+    create_at BIGINT,
+    create_by VARCHAR(255),
+    modify_at BIGINT,
+    modify_by VARCHAR(255)
+);
+
+CREATE TABLE osinfo (
+    os_id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    version VARCHAR(255),
+    kernel VARCHAR(255),
+    create_at BIGINT,
+    create_by VARCHAR(255),
+    modify_at BIGINT,
+    modify_by VARCHAR(255)
+);
+
+CREATE TABLE configs (
+    config_id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    content JSONB,
+    create_at BIGINT,
+    create_by VARCHAR(255),
+    modify_at BIGINT,
+    modify_by VARCHAR(255)
+);
+
+-- dataset statuses define lifecycle of datasets, see DatasetStatusTransitions
+CREATE TABLE dataset_statuses (
+    status_id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    status VARCHAR(16) NOT NULL UNIQUE
+);
+INSERT INTO dataset_statuses (status) VALUES ('VALID'), ('INVALID'), ('DEPRECATED'), ('PRODUCTION');
+
+CREATE TABLE datasets (
+    dataset_id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    did VARCHAR(255) NOT NULL UNIQUE,
+    status VARCHAR(16) NOT NULL DEFAULT 'VALID'
+        REFERENCES dataset_statuses(status) ON UPDATE CASCADE,
+    site_id BIGINT REFERENCES sites(site_id) ON UPDATE CASCADE,
+    processing_id BIGINT REFERENCES processing(processing_id) ON UPDATE CASCADE,
+    os_id BIGINT REFERENCES osinfo(os_id) ON UPDATE CASCADE,
+    config_id BIGINT REFERENCES configs(config_id) ON UPDATE CASCADE,
+    create_at BIGINT,
+    create_by VARCHAR(255),
+    modify_at BIGINT,
+    modify_by VARCHAR(255)
+);
+
+CREATE TABLE parents (
+    parent_id BIGINT NOT NULL REFERENCES datasets(dataset_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    dataset_id BIGINT NOT NULL REFERENCES datasets(dataset_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    create_at BIGINT,
+    create_by VARCHAR(255),
+    modify_at BIGINT,
+    modify_by VARCHAR(255),
+    PRIMARY KEY (dataset_id, parent_id)
+);
+
+CREATE TABLE buckets (
+    bucket_id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    bucket VARCHAR(255) NOT NULL UNIQUE,
+    uuid VARCHAR(255),
+    meta_data TEXT,
+    dataset_id BIGINT REFERENCES datasets(dataset_id) ON UPDATE CASCADE,
+    create_at BIGINT,
+    create_by VARCHAR(255),
+    modify_at BIGINT,
+    modify_by VARCHAR(255)
+);
+
+CREATE TABLE files (
+    file_id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    file VARCHAR(255) NOT NULL UNIQUE,
+    checksum VARCHAR(255),
+    size BIGINT,
+    is_file_valid INTEGER DEFAULT 1,
+    create_at BIGINT,
+    create_by VARCHAR(255),
+    modify_at BIGINT,
+    modify_by VARCHAR(255)
+);
+
+CREATE TABLE environments (
+    environment_id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    version VARCHAR(255),
+    details TEXT,
+    os_id BIGINT REFERENCES osinfo(os_id) ON DELETE SET NULL ON UPDATE CASCADE,
+    parent_environment_id BIGINT REFERENCES environments(environment_id) ON DELETE SET NULL ON UPDATE CASCADE,
+    create_at BIGINT,
+    create_by VARCHAR(255),
+    modify_at BIGINT,
+    modify_by VARCHAR(255)
+);
+
+CREATE TABLE packages (
+    package_id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    version VARCHAR(255),
+    create_at BIGINT,
+    create_by VARCHAR(255),
+    modify_at BIGINT,
+    modify_by VARCHAR(255)
+);
+
+CREATE TABLE scripts (
+    script_id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    order_idx INTEGER,
+    options TEXT,
+    parent_script_id BIGINT REFERENCES scripts(script_id) ON DELETE SET NULL ON UPDATE CASCADE,
+    create_at BIGINT,
+    create_by VARCHAR(255),
+    modify_at BIGINT,
+    modify_by VARCHAR(255)
+);
+
+-- Many-to-many relationships
+
+-- dataset may have input and output files, and file can be present in
+-- different datasets
+CREATE TABLE datasets_files (
+    dataset_id BIGINT NOT NULL REFERENCES datasets(dataset_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    file_id BIGINT NOT NULL REFERENCES files(file_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    file_type VARCHAR(255) NOT NULL,
+    PRIMARY KEY (dataset_id, file_id, file_type)
+);
+
+-- dataset may have many environments, and one environment can be associated
+-- with different datasets
+CREATE TABLE datasets_environments (
+    dataset_id BIGINT NOT NULL REFERENCES datasets(dataset_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    environment_id BIGINT NOT NULL REFERENCES environments(environment_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    PRIMARY KEY (dataset_id, environment_id)
+);
+
+-- dataset may have many scripts, and one script can be associated
+-- with different datasets
+CREATE TABLE datasets_scripts (
+    dataset_id BIGINT NOT NULL REFERENCES datasets(dataset_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    script_id BIGINT NOT NULL REFERENCES scripts(script_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    PRIMARY KEY (dataset_id, script_id)
+);
+
+-- dataset may have many configs, and one config can be associated
+-- with different datasets
+CREATE TABLE datasets_configs (
+    dataset_id BIGINT NOT NULL REFERENCES datasets(dataset_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    config_id BIGINT NOT NULL REFERENCES configs(config_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    PRIMARY KEY (dataset_id, config_id)
+);
+
+-- environment can have multiple python packages and a given package may be
+-- presented in different environments
+CREATE TABLE environments_packages (
+    environment_id BIGINT NOT NULL REFERENCES environments(environment_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    package_id BIGINT NOT NULL REFERENCES packages(package_id) ON DELETE CASCADE ON UPDATE CASCADE,
+    PRIMARY KEY (environment_id, package_id)
+);
+
+-- dataset history keeps snapshots of previous provenance of datasets,
+-- snapshot is taken every time dataset is changed
+CREATE TABLE datasets_history (
+    history_id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    dataset_id BIGINT NOT NULL,
+    did VARCHAR(255) NOT NULL,
+    version INTEGER NOT NULL,
+    provenance TEXT,
+    create_at BIGINT,
+    create_by VARCHAR(255),
+    UNIQUE (did, version)
+);
+
+-- audit log keeps append-only record of every POST/PUT/DELETE API call
+CREATE TABLE audit_log (
+    audit_id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    api VARCHAR(255) NOT NULL,
+    method VARCHAR(16) NOT NULL,
+    uri TEXT,
+    create_by VARCHAR(255),
+    remote_addr VARCHAR(255),
+    payload_hash VARCHAR(64),
+    result VARCHAR(16) NOT NULL,
+    code INTEGER DEFAULT 0,
+    create_at BIGINT
+);
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+    FOR EACH ROW EXECUTE PROCEDURE audit_log_append_only();
+CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+    FOR EACH ROW EXECUTE PROCEDURE audit_log_append_only();
+
+-- schema version keeps applied schema migrations, see static/schema/migrations,
+-- new schema changes should be added as migrations and recorded here
+CREATE TABLE schema_version (
+    version INTEGER PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    applied_at BIGINT
+);
+INSERT INTO schema_version (version, name, applied_at) VALUES
+    (1, 'baseline', CAST(EXTRACT(EPOCH FROM NOW()) AS BIGINT)),
+    (2, 'datasets_history', CAST(EXTRACT(EPOCH FROM NOW()) AS BIGINT)),
+    (3, 'audit_log', CAST(EXTRACT(EPOCH FROM NOW()) AS BIGINT)),
+    (4, 'dataset_status', CAST(EXTRACT(EPOCH FROM NOW()) AS BIGINT)),
+    (5, 'lookup_keys', CAST(EXTRACT(EPOCH FROM NOW()) AS BIGINT)),
+    (6, 'fulltext', CAST(EXTRACT(EPOCH FROM NOW()) AS BIGINT)),
+    (7, 'config_json', CAST(EXTRACT(EPOCH FROM NOW()) AS BIGINT));
+
+-- indexes
+CREATE INDEX idx_datasets_did ON datasets(did);
+CREATE INDEX idx_datasets_status ON datasets(status);
+CREATE INDEX idx_files_file ON files(file);
+CREATE UNIQUE INDEX idx_scripts_name ON scripts(name);
+CREATE UNIQUE INDEX idx_environments_name ON environments(name);
+CREATE INDEX idx_packages_name ON packages(name);
+CREATE INDEX idx_processing_name ON processing(processing);
+CREATE INDEX idx_osinfo_name ON osinfo(name);
+CREATE INDEX idx_osinfo_kernel ON osinfo(kernel);
+CREATE INDEX idx_osinfo_version ON osinfo(version);
+CREATE UNIQUE INDEX idx_osinfo_record ON osinfo(name, version, kernel);
+CREATE INDEX idx_parents_parent ON parents(parent_id);
+CREATE INDEX idx_datasets_history_did ON datasets_history(did);
+CREATE INDEX idx_audit_log_api ON audit_log(api);
+CREATE INDEX idx_audit_log_create_at ON audit_log(create_at);
+CREATE INDEX idx_configs_fts ON configs USING GIN (to_tsvector('simple', COALESCE(content::text, '')));
+CREATE INDEX idx_scripts_fts ON scripts USING GIN (to_tsvector('simple', COALESCE(options, '')));
+CREATE INDEX idx_environments_fts ON environments USING GIN (to_tsvector('simple', COALESCE(details, '')));
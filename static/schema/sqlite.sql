@@ -35,9 +35,16 @@ create TABLE buckets (
     modify_at INTEGER,
     modify_by VARCHAR(255)
 );
+-- dataset statuses define lifecycle of datasets, see DatasetStatusTransitions
+create TABLE dataset_statuses (
+    status_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    status VARCHAR(16) NOT NULL UNIQUE
+);
+INSERT INTO dataset_statuses (status) VALUES ('VALID'), ('INVALID'), ('DEPRECATED'), ('PRODUCTION');
 create TABLE datasets (
     dataset_id INTEGER PRIMARY KEY AUTOINCREMENT,
     did VARCHAR(255) NOT NULL UNIQUE,
+    status VARCHAR(16) NOT NULL DEFAULT 'VALID', -- one of dataset_statuses, validated by DBS server
     site_id INTEGER REFERENCES sites(site_id) ON UPDATE CASCADE,
     processing_id INTEGER REFERENCES processing(processing_id) ON UPDATE CASCADE,
     os_id INTEGER REFERENCES osinfo(os_id) ON UPDATE CASCADE,
@@ -101,6 +108,7 @@ CREATE TABLE scripts (
     modify_at INTEGER,
     modify_by VARCHAR(255)
 );
+-- content of configs is JSON document
 CREATE TABLE configs (
     config_id INTEGER PRIMARY KEY AUTOINCREMENT,
     content TEXT,
@@ -162,14 +170,124 @@ CREATE TABLE environments_packages (
     FOREIGN KEY (package_id) REFERENCES packages(package_id) ON DELETE CASCADE ON UPDATE CASCADE
 );
 
+-- dataset history keeps snapshots of previous provenance of datasets,
+-- snapshot is taken every time dataset is changed
+CREATE TABLE datasets_history (
+    history_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    dataset_id INTEGER NOT NULL,
+    did VARCHAR(255) NOT NULL,
+    version INTEGER NOT NULL,
+    provenance TEXT,
+    create_at INTEGER,
+    create_by VARCHAR(255),
+    UNIQUE (did, version)
+);
+
+-- audit log keeps append-only record of every POST/PUT/DELETE API call
+CREATE TABLE audit_log (
+    audit_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    api VARCHAR(255) NOT NULL,
+    method VARCHAR(16) NOT NULL,
+    uri TEXT,
+    create_by VARCHAR(255),
+    remote_addr VARCHAR(255),
+    payload_hash VARCHAR(64),
+    result VARCHAR(16) NOT NULL,
+    code INTEGER DEFAULT 0,
+    create_at INTEGER
+);
+CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+-- schema version keeps applied schema migrations, see static/schema/migrations,
+-- new schema changes should be added as migrations and recorded here
+CREATE TABLE schema_version (
+    version INTEGER PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    applied_at INTEGER
+);
+INSERT INTO schema_version (version, name, applied_at) VALUES
+    (1, 'baseline', CAST(strftime('%s', 'now') AS INTEGER)),
+    (2, 'datasets_history', CAST(strftime('%s', 'now') AS INTEGER)),
+    (3, 'audit_log', CAST(strftime('%s', 'now') AS INTEGER)),
+    (4, 'dataset_status', CAST(strftime('%s', 'now') AS INTEGER)),
+    (5, 'lookup_keys', CAST(strftime('%s', 'now') AS INTEGER)),
+    (6, 'fulltext', CAST(strftime('%s', 'now') AS INTEGER)),
+    (7, 'config_json', CAST(strftime('%s', 'now') AS INTEGER));
+
 -- indexes
 CREATE INDEX idx_datasets_did ON datasets(did);
+CREATE INDEX idx_datasets_status ON datasets(status);
 CREATE INDEX idx_files_file ON files(file);
-CREATE INDEX idx_scripts_name ON scripts(name);
-CREATE INDEX idx_environments_name ON environments(name);
+CREATE UNIQUE INDEX idx_scripts_name ON scripts(name);
+CREATE UNIQUE INDEX idx_environments_name ON environments(name);
 CREATE INDEX idx_packages_name ON packages(name);
 CREATE INDEX idx_processing_name ON processing(processing);
 CREATE INDEX idx_osinfo_name ON osinfo(name);
 CREATE INDEX idx_osinfo_kernel ON osinfo(kernel);
 CREATE INDEX idx_osinfo_version ON osinfo(version);
+CREATE UNIQUE INDEX idx_osinfo_record ON osinfo(name, version, kernel);
+CREATE INDEX idx_datasets_history_did ON datasets_history(did);
+CREATE INDEX idx_audit_log_api ON audit_log(api);
+CREATE INDEX idx_audit_log_create_at ON audit_log(create_at);
 
+-- full-text indexes of free-form text of configs, scripts and environments,
+-- FTS4 external content tables refer to rows of indexed tables by their ids
+-- and are kept in sync by triggers
+CREATE VIRTUAL TABLE configs_fts USING fts4(content="configs", content);
+CREATE TRIGGER configs_fts_bu BEFORE UPDATE ON configs
+BEGIN
+    DELETE FROM configs_fts WHERE docid = old.config_id;
+END;
+CREATE TRIGGER configs_fts_bd BEFORE DELETE ON configs
+BEGIN
+    DELETE FROM configs_fts WHERE docid = old.config_id;
+END;
+CREATE TRIGGER configs_fts_au AFTER UPDATE ON configs
+BEGIN
+    INSERT INTO configs_fts (docid, content) VALUES (new.config_id, new.content);
+END;
+CREATE TRIGGER configs_fts_ai AFTER INSERT ON configs
+BEGIN
+    INSERT INTO configs_fts (docid, content) VALUES (new.config_id, new.content);
+END;
+CREATE VIRTUAL TABLE scripts_fts USING fts4(content="scripts", options);
+CREATE TRIGGER scripts_fts_bu BEFORE UPDATE ON scripts
+BEGIN
+    DELETE FROM scripts_fts WHERE docid = old.script_id;
+END;
+CREATE TRIGGER scripts_fts_bd BEFORE DELETE ON scripts
+BEGIN
+    DELETE FROM scripts_fts WHERE docid = old.script_id;
+END;
+CREATE TRIGGER scripts_fts_au AFTER UPDATE ON scripts
+BEGIN
+    INSERT INTO scripts_fts (docid, options) VALUES (new.script_id, new.options);
+END;
+CREATE TRIGGER scripts_fts_ai AFTER INSERT ON scripts
+BEGIN
+    INSERT INTO scripts_fts (docid, options) VALUES (new.script_id, new.options);
+END;
+CREATE VIRTUAL TABLE environments_fts USING fts4(content="environments", details);
+CREATE TRIGGER environments_fts_bu BEFORE UPDATE ON environments
+BEGIN
+    DELETE FROM environments_fts WHERE docid = old.environment_id;
+END;
+CREATE TRIGGER environments_fts_bd BEFORE DELETE ON environments
+BEGIN
+    DELETE FROM environments_fts WHERE docid = old.environment_id;
+END;
+CREATE TRIGGER environments_fts_au AFTER UPDATE ON environments
+BEGIN
+    INSERT INTO environments_fts (docid, details) VALUES (new.environment_id, new.details);
+END;
+CREATE TRIGGER environments_fts_ai AFTER INSERT ON environments
+BEGIN
+    INSERT INTO environments_fts (docid, details) VALUES (new.environment_id, new.details);
+END;
@@ -0,0 +1,2 @@
+DELETE FROM datasets
+WHERE dataset_id = :dataset_id
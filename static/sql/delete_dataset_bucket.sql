@@ -0,0 +1,2 @@
+DELETE FROM buckets
+WHERE dataset_id = :dataset_id
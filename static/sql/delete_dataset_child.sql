@@ -0,0 +1,2 @@
+DELETE FROM parents
+WHERE parent_id = :parent_id
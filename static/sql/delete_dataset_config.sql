@@ -0,0 +1,2 @@
+DELETE FROM datasets_configs
+WHERE dataset_id = :dataset_id
@@ -0,0 +1,2 @@
+DELETE FROM datasets_environments
+WHERE dataset_id = :dataset_id
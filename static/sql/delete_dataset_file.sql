@@ -0,0 +1,2 @@
+DELETE FROM datasets_files
+WHERE dataset_id = :dataset_id
@@ -0,0 +1,2 @@
+DELETE FROM parents
+WHERE dataset_id = :dataset_id
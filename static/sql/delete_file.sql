@@ -0,0 +1,2 @@
+DELETE FROM files
+WHERE file_id = :file_id
@@ -0,0 +1,2 @@
+DELETE FROM datasets_files
+WHERE file_id = :file_id
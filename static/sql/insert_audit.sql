@@ -0,0 +1,6 @@
+INSERT INTO audit_log
+    (api,method,uri,create_by,remote_addr,
+     payload_hash,result,code,create_at)
+    VALUES
+    (:api,:method,:uri,:create_by,:remote_addr,
+     :payload_hash,:result,:code,:create_at)
@@ -2,3 +2,9 @@ INSERT INTO datasets_environments
     (dataset_id,environment_id)
     VALUES
     (:dataset_id,:environment_id)
+{{if eq .Owner "postgres"}}
+    ON CONFLICT DO NOTHING
+{{end}}
+{{if eq .Owner "mysql"}}
+    ON DUPLICATE KEY UPDATE dataset_id = dataset_id
+{{end}}
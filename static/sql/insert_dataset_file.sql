@@ -2,3 +2,9 @@ INSERT INTO datasets_files
     (dataset_id,file_id,file_type)
     VALUES
     (:dataset_id,:file_id,:file_type)
+{{if eq .Owner "postgres"}}
+    ON CONFLICT DO NOTHING
+{{end}}
+{{if eq .Owner "mysql"}}
+    ON DUPLICATE KEY UPDATE dataset_id = dataset_id
+{{end}}
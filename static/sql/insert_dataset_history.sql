@@ -0,0 +1,6 @@
+INSERT INTO datasets_history
+    (dataset_id,did,version,provenance,
+     create_at,create_by)
+    VALUES
+    (:dataset_id,:did,:version,:provenance,
+     :create_at,:create_by)
@@ -2,3 +2,9 @@ INSERT INTO environments_packages
     (environment_id,package_id)
     VALUES
     (:environment_id,:package_id)
+{{if eq .Owner "postgres"}}
+    ON CONFLICT DO NOTHING
+{{end}}
+{{if eq .Owner "mysql"}}
+    ON DUPLICATE KEY UPDATE environment_id = environment_id
+{{end}}
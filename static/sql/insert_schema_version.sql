@@ -0,0 +1,4 @@
+INSERT INTO schema_version
+    (version,name,applied_at)
+    VALUES
+    (:version,:name,:applied_at)
@@ -0,0 +1,12 @@
+SELECT
+    a.audit_id,
+    a.api,
+    a.method,
+    a.uri,
+    a.create_by,
+    a.remote_addr,
+    a.payload_hash,
+    a.result,
+    a.code,
+    a.create_at
+FROM audit_log a
@@ -1,9 +1,14 @@
 SELECT DISTINCT
+{{if .Ids}}
+    d.dataset_id
+{{else}}
     d.did,
+    d.status,
     d.create_by,
     d.create_at,
     d.modify_by,
     d.modify_at
+{{end}}
 FROM datasets d
 {{if .Sites}}
 LEFT JOIN sites s on s.site_id=d.site_id
@@ -21,7 +26,8 @@ LEFT JOIN packages pk ON ep.package_id = pk.package_id
 LEFT JOIN osinfo o ON d.os_id = o.os_id
 {{end}}
 {{if .Config}}
-LEFT JOIN config c ON d.config_id = c.config_id
+LEFT JOIN datasets_configs dc ON d.dataset_id = dc.dataset_id
+LEFT JOIN configs c ON dc.config_id = c.config_id
 {{end}}
 {{if .Scripts}}
 LEFT JOIN datasets_scripts ds ON d.dataset_id = ds.dataset_id
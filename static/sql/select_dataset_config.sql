@@ -0,0 +1,4 @@
+SELECT c.content
+FROM datasets_configs dc
+JOIN configs c ON dc.config_id = c.config_id
+WHERE dc.dataset_id = :dataset_id
@@ -0,0 +1,8 @@
+SELECT
+    h.version,
+    h.provenance,
+    h.create_at,
+    h.create_by
+FROM datasets_history h
+WHERE h.did = :did
+ORDER BY h.version
@@ -0,0 +1,3 @@
+SELECT COALESCE(MAX(h.version), 0)
+FROM datasets_history h
+WHERE h.did = :did
@@ -0,0 +1,7 @@
+SELECT DISTINCT
+    p.dataset_id,
+    p.parent_id,
+    pd.did AS parent_did
+FROM parents p
+JOIN datasets d ON p.dataset_id = d.dataset_id
+JOIN datasets pd ON p.parent_id = pd.dataset_id
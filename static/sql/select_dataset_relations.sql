@@ -0,0 +1,24 @@
+-- scripts go first to define integer type of num_attr column in PostgreSQL
+SELECT 'scripts' AS relation, sc.name, sc.options AS attr, NULL AS text_attr, sc.order_idx AS num_attr
+FROM datasets_scripts ds
+JOIN scripts sc ON ds.script_id = sc.script_id
+WHERE ds.dataset_id = :dataset_id
+UNION ALL
+SELECT df.file_type AS relation, f.file, f.checksum, NULL, f.size
+FROM datasets_files df
+JOIN files f ON df.file_id = f.file_id
+WHERE df.dataset_id = :dataset_id
+UNION ALL
+SELECT 'environments' AS relation, e.name, e.version, e.details, NULL
+FROM datasets_environments de
+JOIN environments e ON de.environment_id = e.environment_id
+WHERE de.dataset_id = :dataset_id
+UNION ALL
+SELECT 'buckets' AS relation, b.bucket, b.uuid, b.meta_data, NULL
+FROM buckets b
+WHERE b.dataset_id = :dataset_id
+UNION ALL
+SELECT 'parent_dids' AS relation, pd.did, NULL, NULL, NULL
+FROM parents pa
+JOIN datasets pd ON pa.parent_id = pd.dataset_id
+WHERE pa.dataset_id = :dataset_id
@@ -0,0 +1,12 @@
+SELECT
+    s.site,
+    p.processing,
+    o.name AS os_name,
+    o.version AS os_version,
+    o.kernel AS os_kernel,
+    d.status
+FROM datasets d
+LEFT JOIN sites s ON d.site_id = s.site_id
+LEFT JOIN processing p ON d.processing_id = p.processing_id
+LEFT JOIN osinfo o ON d.os_id = o.os_id
+WHERE d.dataset_id = :dataset_id
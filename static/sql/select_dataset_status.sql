@@ -0,0 +1,5 @@
+SELECT
+    d.dataset_id,
+    d.status
+FROM datasets d
+WHERE d.did = :did
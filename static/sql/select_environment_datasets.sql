@@ -0,0 +1,4 @@
+SELECT COUNT(DISTINCT de.dataset_id)
+FROM datasets_environments de
+JOIN environments e ON e.environment_id = de.environment_id
+WHERE e.name = :name AND de.dataset_id <> :dataset_id
@@ -0,0 +1,4 @@
+SELECT COUNT(DISTINCT df.dataset_id)
+FROM datasets_files df
+JOIN files f ON f.file_id = df.file_id
+WHERE f.file = :file AND df.dataset_id <> :dataset_id
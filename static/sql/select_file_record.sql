@@ -0,0 +1,12 @@
+SELECT
+    f.file_id,
+    f.file,
+    f.checksum,
+    f.size,
+    f.is_file_valid,
+    f.create_at,
+    f.create_by,
+    f.modify_at,
+    f.modify_by
+FROM files f
+WHERE f.file = :file
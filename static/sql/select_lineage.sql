@@ -0,0 +1,40 @@
+WITH RECURSIVE lineage(dataset_id, parent_id, depth) AS (
+{{if .Up}}
+    SELECT p.dataset_id, p.parent_id, 1
+    FROM parents p
+    WHERE p.dataset_id = :dataset_id
+    UNION
+    SELECT p.dataset_id, p.parent_id, l.depth + 1
+    FROM parents p
+    JOIN lineage l ON p.dataset_id = l.parent_id
+    WHERE l.depth < :depth
+{{else}}
+    SELECT p.dataset_id, p.parent_id, 1
+    FROM parents p
+    WHERE p.parent_id = :dataset_id
+    UNION
+    SELECT p.dataset_id, p.parent_id, l.depth + 1
+    FROM parents p
+    JOIN lineage l ON p.parent_id = l.dataset_id
+    WHERE l.depth < :depth
+{{end}}
+)
+SELECT
+    d.did,
+    d.create_at,
+    d.create_by,
+    d.modify_at,
+    d.modify_by,
+    pd.did AS parent_did,
+    pd.create_at AS parent_create_at,
+    pd.create_by AS parent_create_by,
+    pd.modify_at AS parent_modify_at,
+    pd.modify_by AS parent_modify_by,
+    MIN(l.depth) AS depth
+FROM lineage l
+JOIN datasets d ON d.dataset_id = l.dataset_id
+JOIN datasets pd ON pd.dataset_id = l.parent_id
+GROUP BY
+    d.did, d.create_at, d.create_by, d.modify_at, d.modify_by,
+    pd.did, pd.create_at, pd.create_by, pd.modify_at, pd.modify_by
+ORDER BY depth, d.did, pd.did
@@ -0,0 +1,8 @@
+SELECT DISTINCT
+    f.file,
+    df.file_type
+FROM datasets d
+JOIN datasets_files df ON d.dataset_id = df.dataset_id
+JOIN files f ON f.file_id = df.file_id
+WHERE d.did = :did
+ORDER BY df.file_type, f.file
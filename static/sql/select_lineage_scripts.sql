@@ -0,0 +1,10 @@
+SELECT DISTINCT
+    pr.processing,
+    s.name AS script_name,
+    s.order_idx
+FROM datasets d
+LEFT JOIN processing pr ON pr.processing_id = d.processing_id
+LEFT JOIN datasets_scripts ds ON d.dataset_id = ds.dataset_id
+LEFT JOIN scripts s ON ds.script_id = s.script_id
+WHERE d.did = :did
+ORDER BY s.order_idx, s.name
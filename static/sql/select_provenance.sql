@@ -1,5 +1,6 @@
 SELECT DISTINCT
     d.did AS dataset_did, 
+    d.dataset_id,
     p.processing,
     o.name AS os_name,
     o.kernel AS os_kernel,
@@ -10,6 +11,7 @@ SELECT DISTINCT
     e.details AS environment_details,
     pe.name AS parent_environment_name,
     eo.name AS env_os_name,
+    pk.package_id,
     pk.name AS package_name,
     pk.version AS package_version,
     sc.script_id,
@@ -27,8 +29,8 @@ LEFT JOIN processing p ON d.processing_id = p.processing_id
 LEFT JOIN sites s ON d.site_id = s.site_id
 
 -- configs
-LEFT JOIN configs c ON d.config_id = c.config_id
 LEFT JOIN datasets_configs dc ON d.dataset_id = dc.dataset_id
+LEFT JOIN configs c ON dc.config_id = c.config_id
 
 -- environments
 LEFT JOIN datasets_environments de ON d.dataset_id = de.dataset_id
@@ -0,0 +1,6 @@
+SELECT
+    v.version,
+    v.name,
+    v.applied_at
+FROM schema_version v
+ORDER BY v.version
@@ -0,0 +1,4 @@
+SELECT COUNT(DISTINCT ds.dataset_id)
+FROM datasets_scripts ds
+JOIN scripts sc ON sc.script_id = ds.script_id
+WHERE sc.name = :name AND ds.dataset_id <> :dataset_id
@@ -0,0 +1 @@
+SELECT status FROM dataset_statuses WHERE status = :status
@@ -1,7 +1,7 @@
 UPDATE datasets
-SET site_id = :site_id,
-    processing_id = :processing_id,
-    parent_id = :parent_id,
+SET site_id = COALESCE(:site_id, site_id),
+    processing_id = COALESCE(:processing_id, processing_id),
+    os_id = COALESCE(:os_id, os_id),
     modify_at = :modify_at,
     modify_by = :modify_by
 WHERE dataset_id = :dataset_id
@@ -0,0 +1,10 @@
+UPDATE files
+SET is_file_valid = :is_file_valid,
+    modify_at = :modify_at,
+    modify_by = :modify_by
+WHERE file_id IN (
+    SELECT df.file_id
+    FROM datasets_files df
+    JOIN datasets d ON d.dataset_id = df.dataset_id
+    WHERE d.did = :did
+)
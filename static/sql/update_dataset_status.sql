@@ -0,0 +1,5 @@
+UPDATE datasets
+SET status = :status,
+    modify_at = :modify_at,
+    modify_by = :modify_by
+WHERE dataset_id = :dataset_id
@@ -0,0 +1,6 @@
+UPDATE environments
+SET version = :version,
+    details = :details,
+    modify_at = :modify_at,
+    modify_by = :modify_by
+WHERE name = :name
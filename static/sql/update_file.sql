@@ -0,0 +1,7 @@
+UPDATE files
+SET checksum = :checksum,
+    size = :size,
+    is_file_valid = :is_file_valid,
+    modify_at = :modify_at,
+    modify_by = :modify_by
+WHERE file_id = :file_id
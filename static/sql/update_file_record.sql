@@ -0,0 +1,6 @@
+UPDATE files
+SET checksum = :checksum,
+    size = :size,
+    modify_at = :modify_at,
+    modify_by = :modify_by
+WHERE file = :file
@@ -0,0 +1,5 @@
+UPDATE files
+SET is_file_valid = :is_file_valid,
+    modify_at = :modify_at,
+    modify_by = :modify_by
+WHERE file = :file
@@ -0,0 +1,6 @@
+UPDATE scripts
+SET options = :options,
+    order_idx = :order_idx,
+    modify_at = :modify_at,
+    modify_by = :modify_by
+WHERE name = :name
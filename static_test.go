@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/CHESSComputing/DataBookkeeping/dbs"
+)
+
+// TestStaticArea tests on-disk override of embedded static area
+func TestStaticArea(t *testing.T) {
+	staticDir := dbs.StaticDir
+	defer func() { dbs.StaticDir = staticDir }()
+
+	// embedded static area
+	dbs.StaticDir = ""
+	files, err := dbs.ListStaticFiles("sql")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("unable to list embedded SQL files, %v", err)
+	}
+	stm, err := dbs.ParseTmpl("sql", "select_dataset.sql", map[string]any{"Owner": "sqlite"})
+	if err != nil || !strings.Contains(stm, "FROM datasets") {
+		t.Fatalf("unable to parse embedded template, %v", err)
+	}
+	if _, err := dbs.ParseTmpl("sql", "no_such_file.sql", nil); err == nil {
+		t.Fatal("missing template should yield an error")
+	}
+
+	// on-disk files override embedded ones and add new ones
+	sdir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(sdir, "sql"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"select_dataset.sql": "SELECT did FROM override",
+		"select_extra.sql":   "SELECT 1",
+	} {
+		if err := os.WriteFile(filepath.Join(sdir, "sql", name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dbs.StaticDir = sdir
+	stm, err = dbs.ParseTmpl("sql", "select_dataset.sql", nil)
+	if err != nil || stm != "SELECT did FROM override" {
+		t.Fatalf("on-disk template is not used, statement %s error %v", stm, err)
+	}
+	merged, err := dbs.ListStaticFiles("sql")
+	if err != nil || len(merged) != len(files)+1 {
+		t.Fatalf("wrong number of merged SQL files %d, error %v", len(merged), err)
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/CHESSComputing/DataBookkeeping/dbs"
+)
+
+// helper function to insert dataset record in given mode and return API output
+func insertDatasetMode(t *testing.T, rec map[string]any, mode string) string {
+	out, err := datasetMode(t, rec, mode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// helper function to call dataset insert API in given mode
+func datasetMode(t *testing.T, rec map[string]any, mode string) (string, error) {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	api := dbs.API{
+		Reader:      bytes.NewReader(data),
+		Writer:      w,
+		ContentType: "application/json",
+		Params:      map[string]any{"mode": []string{mode}},
+		CreateBy:    "test",
+		Api:         "dataset",
+	}
+	err = api.InsertDataset()
+	return w.Body.String(), err
+}
+
+// TestUpsertRelationAttributes tests that verify and upsert modes compare
+// attributes of environments, scripts and files along with their names and
+// that upsert does not change attributes of records shared among datasets
+func TestUpsertRelationAttributes(t *testing.T) {
+	_, restore := initTestDB(t, "upsert.db")
+	defer restore()
+
+	rec := map[string]any{
+		"did":          "/beamline=3a/btr=upsert/cycle=2024-3/sample_name=attrs",
+		"osinfo":       map[string]any{"name": "linux-cc7", "kernel": "1-2-3", "version": "cc7-123"},
+		"environments": []map[string]any{{"name": "conda-attrs", "version": "1.0", "details": "details"}},
+		"processing":   "attrs-processing",
+		"site":         "Cornell",
+		"scripts":      []map[string]any{{"name": "attrs-script", "options": "-m", "order_idx": 1}},
+		"input_files":  []map[string]any{{"name": "/attrs/input.png", "checksum": "abc", "size": 10}},
+	}
+	if out := insertDatasetMode(t, rec, "create"); !strings.Contains(out, `"status":"created"`) {
+		t.Fatalf("dataset is not created: %s", out)
+	}
+
+	rec["environments"] = []map[string]any{{"name": "conda-attrs", "version": "2.0", "details": "details"}}
+	rec["scripts"] = []map[string]any{{"name": "attrs-script", "options": "-p", "order_idx": 2}}
+	rec["input_files"] = []map[string]any{{"name": "/attrs/input.png", "checksum": "def", "size": 10}}
+	out := insertDatasetMode(t, rec, "verify")
+	for _, s := range []string{
+		`"status":"differs"`,
+		`{"field":"environments","changed":[{"name":"conda-attrs","attribute":"version","stored":"1.0","submitted":"2.0"}]}`,
+		`{"name":"attrs-script","attribute":"options","stored":"-m","submitted":"-p"}`,
+		`{"name":"attrs-script","attribute":"order_idx","stored":"1","submitted":"2"}`,
+		`{"field":"input_files","changed":[{"name":"/attrs/input.png","attribute":"checksum","stored":"abc","submitted":"def"}]}`,
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("verify report does not contain %s:\n%s", s, out)
+		}
+	}
+
+	if out := insertDatasetMode(t, rec, "upsert"); !strings.Contains(out, `"status":"updated"`) {
+		t.Fatalf("dataset is not updated: %s", out)
+	}
+	if out := insertDatasetMode(t, rec, "verify"); !strings.Contains(out, `"status":"unchanged"`) {
+		t.Errorf("dataset differs after upsert: %s", out)
+	}
+
+	// checksum and size of files are compared only if provided
+	rec["input_files"] = []map[string]any{{"name": "/attrs/input.png"}}
+	if out := insertDatasetMode(t, rec, "verify"); !strings.Contains(out, `"status":"unchanged"`) {
+		t.Errorf("dataset without file attributes differs: %s", out)
+	}
+
+	// records used by other dataset keep their attributes
+	shared := map[string]any{
+		"did":          "/beamline=3a/btr=upsert/cycle=2024-3/sample_name=shared",
+		"osinfo":       rec["osinfo"],
+		"environments": []map[string]any{{"name": "conda-attrs", "version": "2.0", "details": "details"}},
+		"processing":   "attrs-processing",
+		"site":         "Cornell",
+		"scripts":      []map[string]any{{"name": "attrs-script", "options": "-p", "order_idx": 2}},
+		"input_files":  []map[string]any{{"name": "/attrs/input.png", "checksum": "def", "size": 10}},
+	}
+	insertDatasetMode(t, shared, "create")
+	for key, val := range map[string][]map[string]any{
+		"environments": {{"name": "conda-attrs", "version": "3.0", "details": "details"}},
+		"scripts":      {{"name": "attrs-script", "options": "-q", "order_idx": 2}},
+		"input_files":  {{"name": "/attrs/input.png", "checksum": "ghi", "size": 10}},
+	} {
+		upd := make(map[string]any)
+		for k, v := range rec {
+			upd[k] = v
+		}
+		upd[key] = val
+		_, err := datasetMode(t, upd, "upsert")
+		if err == nil || !strings.Contains(err.Error(), "other dataset") {
+			t.Errorf("upsert of shared %s should fail, error %v", key, err)
+		}
+	}
+	if out := insertDatasetMode(t, shared, "verify"); !strings.Contains(out, `"status":"unchanged"`) {
+		t.Errorf("shared records are changed by upsert of other dataset: %s", out)
+	}
+}
+
+// TestUpdateDatasetKeepsIds tests that dataset update keeps stored ids which
+// are not provided in the payload
+func TestUpdateDatasetKeepsIds(t *testing.T) {
+	tdb, restore := initTestDB(t, "update.db")
+	defer restore()
+
+	did := "/beamline=3a/btr=update/cycle=2024-3/sample_name=ids"
+	rec := map[string]any{
+		"did":        did,
+		"osinfo":     map[string]any{"name": "linux-cc7", "kernel": "1-2-3", "version": "cc7-123"},
+		"processing": "update-processing",
+		"site":       "Cornell",
+	}
+	insertDatasetMode(t, rec, "create")
+	var datasetId, siteId, processingId, osId int64
+	stm := "SELECT dataset_id, site_id, processing_id, os_id FROM datasets WHERE did = ?"
+	if err := tdb.QueryRow(stm, did).Scan(&datasetId, &siteId, &processingId, &osId); err != nil {
+		t.Fatal(err)
+	}
+
+	// new site is provided while processing and os info are not
+	if _, err := tdb.Exec("INSERT INTO sites (site) VALUES ('CHESS')"); err != nil {
+		t.Fatal(err)
+	}
+	var newSiteId int64
+	if err := tdb.QueryRow("SELECT site_id FROM sites WHERE site = 'CHESS'").Scan(&newSiteId); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(map[string]any{
+		"dataset_id": datasetId, "did": did, "site_id": newSiteId,
+		"create_at": 1700000000, "create_by": "test", "modify_at": 1700000000, "modify_by": "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := dbs.API{
+		Reader:      bytes.NewReader(data),
+		Writer:      httptest.NewRecorder(),
+		ContentType: "application/json",
+		Params:      make(map[string]any),
+		CreateBy:    "test",
+		Api:         "dataset",
+	}
+	if err := api.UpdateDataset(); err != nil {
+		t.Fatal(err)
+	}
+	var sid, pid, oid int64
+	stm = "SELECT site_id, processing_id, os_id FROM datasets WHERE dataset_id = ?"
+	if err := tdb.QueryRow(stm, datasetId).Scan(&sid, &pid, &oid); err != nil {
+		t.Fatal(err)
+	}
+	if sid != newSiteId || pid != processingId || oid != osId {
+		t.Errorf("wrong ids after update: site %d processing %d os %d, expect %d %d %d",
+			sid, pid, oid, newSiteId, processingId, osId)
+	}
+}
+
+// TestInsertRelationDuplicates tests that relationships of existing dataset
+// can be inserted again by upsert on MySQL and PostgreSQL
+func TestInsertRelationDuplicates(t *testing.T) {
+	_, restore := initTestDB(t, "duplicates.db")
+	defer restore()
+
+	clauses := map[string]string{
+		"mysql":    "ON DUPLICATE KEY UPDATE",
+		"postgres": "ON CONFLICT DO NOTHING",
+	}
+	for owner, clause := range clauses {
+		dbsql := dbs.LoadSQL(owner)
+		for _, tmpl := range []string{
+			"insert_dataset_config", "insert_dataset_environment", "insert_dataset_file",
+			"insert_dataset_script", "insert_environment_package"} {
+			if stm := dbsql[tmpl].(string); !strings.Contains(stm, clause) {
+				t.Errorf("%s %s statement does not ignore duplicates:\n%s", owner, tmpl, stm)
+			}
+		}
+	}
+}